@@ -1,9 +1,6 @@
 package calculator
 
 import (
-	"encoding/json"
-	"fmt"
-	"log"
 	"workload-estimator-poc/models"
 	"workload-estimator-poc/services"
 )
@@ -12,80 +9,108 @@ const CLUSTER_OPTION = "Custom"
 
 func EstimateResources(request models.ComputeRequest) models.ComputeResponse {
 
-	// Convert Average Document Size from KB to Bytes
-	request.Dataset.AverageDocumentSize *= 1024
+	// Document sizes arrive in bytes, models.Dataset resolves their units while the request is decoded
 
-	// Log the received request
-	requestJSON, err := json.MarshalIndent(request, "", "  ")
-	if err == nil {
-		log.Printf("Received Request: \n%s\n", string(requestJSON))
-	} else {
-		log.Println("Failed to log request")
+	// Collection overrides are part of the dataset, which holds at least their documents
+	var collectionDocuments int64
+	for _, collection := range request.Dataset.Collections {
+		collectionDocuments += max(collection.NoOfDocuments, 0)
+	}
+	request.Dataset.NoOfDocuments = max(request.Dataset.NoOfDocuments, collectionDocuments)
+
+	// A document size distribution replaces the average document size for every service
+	if histogram := services.DocumentSizeHistogram(request.Dataset); len(histogram) > 0 {
+		request.Dataset.AverageDocumentSize = models.ByteSize(services.AverageDocumentSize(request.Dataset))
 	}
 
 	// Apply default values
 	applyDefaults(&request)
 
-	// Log the received request
-	requestJSON2, err := json.MarshalIndent(request, "", "  ")
-	if err == nil {
-		log.Printf("Received Request: \n%s\n", string(requestJSON2))
-	} else {
-		log.Println("Failed to log request")
-	}
+	// Load other components add to the Data service
+	dataOverhead := dataServiceOverhead(request)
+
+	// Recommend the resident ratio from the access pattern, it replaces the user's guess
+	var workingSet *models.WorkingSetResult
+	request.Dataset.ResidentRatio, workingSet = residentRatio(request, dataOverhead)
+
+	// The workload rates are averages, memory and disk are sized for them and CPU and disk IO for the peak of the profile
+	profile := workloadProfile(request.Workload.Profile)
+	cpuPeak, diskIOPeak := request, request
+	cpuPeak.Workload = scaleWorkload(request.Workload, profile.cpuPeakFactor)
+	diskIOPeak.Workload = scaleWorkload(request.Workload, profile.diskIOPeakFactor)
+	cpuPeakOverhead, diskIOPeakOverhead := dataServiceOverhead(cpuPeak), dataServiceOverhead(diskIOPeak)
 
 	var serviceGroupResults []models.ServiceGroupResult
 	var nodesAllocated int64 = 0
+	var clusterTotal models.Resources
+	var dataRAM, dataCPU float64
+	var dataNodes int64
+	var dataGroup string
+	var tombstones *models.TombstoneResult
+	var zoneTraffic *models.InterZoneTraffic
 	var servicesAll []string
 
+	// Resolve the utilisation the nodes are sized for
+	targets, warnings := targetUtilization(request.TargetUtilization)
+
 	// Iterate over service groups
 	for _, group := range request.ServiceGroups {
-		nodes := group.NoOfNodes
-		nodesAllocated += nodes
+		// the demand is spread over the nodes left after the tolerated failure
+		nodes := sizingNodes(group)
+		nodesAllocated += group.NoOfNodes
 		servicesAll = append(servicesAll, group.Services...)
 
 		// Lists to store resources for each service in the current group
 		var ramList, cpuList, diskList, diskIOList []float64
+		var averageCPUList, averageDiskIOList []float64
+		var writeLatency, compactionThroughput float64
+		var dataCopies int64
 
 		// Iterate over services within the service group
 		for _, service := range group.Services {
-			var ram, cpu, disk, diskIO float64
-
-			// Call the corresponding service calculator
-			switch service {
-			case "data":
-				ram, cpu, disk, diskIO = services.EstimateResourcesForData(request.Dataset, request.Workload)
-			case "index":
-				ram, cpu, disk, diskIO = services.EstimateResourcesForIndex(request.Dataset, request.Workload)
-			case "query":
-				ram, cpu, disk, diskIO = services.EstimateResourcesForQuery(request.Dataset, request.Workload)
-			case "search":
-				ram, cpu, disk, diskIO = services.EstimateResourcesForSearch(request.Dataset, request.Workload)
-			case "eventing":
-				ram, cpu, disk, diskIO = services.EstimateResourcesForEventing(request.Dataset, request.Workload)
-			case "analytics":
-				ram, cpu, disk, diskIO = services.EstimateResourcesForAnalytics(request.Dataset, request.Workload)
+			ram, cpu, disk, diskIO := estimateService(service, request, dataOverhead)
+			_, peakCPU, _, _ := estimateService(service, cpuPeak, cpuPeakOverhead)
+			_, _, _, peakDiskIO := estimateService(service, diskIOPeak, diskIOPeakOverhead)
+
+			if service == "data" {
+				dataRAM = ram
+				dataCPU = services.DataCPUDemand(cpuPeak.Dataset, cpuPeak.Workload, cpuPeakOverhead)
+				dataNodes += group.NoOfNodes
+				dataGroup = groupName(group)
+				zoneTraffic = interZoneTraffic(request, dataOverhead, availabilityZones(group))
+				dataCopies = services.BucketReplicas(request.Dataset) + 1
+				tombstones = &models.TombstoneResult{
+					PurgeIntervalDays: services.PurgeIntervalDays(request.Dataset),
+					DiskSpace:         services.TombstoneSpace(request.Dataset, request.Workload, request.DCPConsumers.XDCRReplications),
+				}
+				writeLatency = services.EstimateWriteLatency(request.Dataset, group.DiskType)
+				if nodes > 0 {
+					compactionThroughput = services.Round(services.CompactionThroughput(request.Dataset, request.Workload)/float64(nodes), 2)
+				}
 			}
 
 			// Add resources to corresponding lists
 			ramList = append(ramList, ram)
-			cpuList = append(cpuList, cpu)
+			cpuList = append(cpuList, peakCPU)
 			diskList = append(diskList, disk)
-			diskIOList = append(diskIOList, diskIO)
+			diskIOList = append(diskIOList, peakDiskIO)
+			averageCPUList = append(averageCPUList, cpu)
+			averageDiskIOList = append(averageDiskIOList, diskIO)
 		}
 
-		// Print the lists for debugging
-		fmt.Printf("\nService Group: %v\n", group.Services)
-		fmt.Printf("RAM List: %v\n", ramList)
-		fmt.Printf("CPU List: %v\n", cpuList)
-		fmt.Printf("Disk List: %v\n", diskList)
-		fmt.Printf("Disk IO List: %v\n\n", diskIOList)
+		// Warn about the demands the per-node limits cap
+		warnings = append(warnings, groupWarnings(group, nodes, ramList, cpuList, diskList, diskIOList)...)
+		warnings = append(warnings, failoverWarnings(group)...)
+		warnings = append(warnings, allocationWarnings(group)...)
+		warnings = append(warnings, zoneWarnings(group, dataCopies)...)
 
 		// Calculate the total resources for this service group
-		totalRAM := CalculateRAM(ramList, group.Services, nodes)
-		totalCPU := CalculateCPU(cpuList, nodes)
-		totalDisk := CalculateDisk(diskList, nodes)
-		totalDiskIO := CalculateDiskIO(diskIOList, nodes, group.DiskType)
+		totalRAM := CalculateRAM(ramList, group.Services, nodes, group.MemoryAllocation, targets.RAM)
+		totalCPU := CalculateCPU(cpuList, nodes, targets.CPU)
+		totalDisk := CalculateDisk(diskList, nodes, targets.Disk)
+		totalDiskIO := CalculateDiskIO(diskIOList, nodes, group.DiskType, targets.DiskIO)
+
+		serviceQuotas := CalculateServiceQuotas(ramList, cpuList, diskList, diskIOList, group.Services, nodes, group.MemoryAllocation)
 
 		// Find the closest instance for RAM and CPU
 		selectedInstance := findClosestInstance(totalCPU, totalRAM)
@@ -94,15 +119,51 @@ func EstimateResources(request models.ComputeRequest) models.ComputeResponse {
 		totalRAM = float64(selectedInstance.RAM)
 		totalCPU = float64(selectedInstance.VCPU)
 
+		perNode := models.Resources{
+			RAM:    int64(totalRAM),
+			CPU:    int64(totalCPU),
+			Disk:   int64(totalDisk),
+			DiskIO: int64(totalDiskIO),
+		}
+		groupTotal := perNode.Scale(group.NoOfNodes)
+
+		// Utilisation of the chosen nodes at the average and the peak workload, with every node running and after the tolerated failure
+		demand := models.Utilization{
+			RAM:    CalculateRAM(ramList, group.Services, 1, group.MemoryAllocation, 100),
+			CPU:    sumOf(averageCPUList),
+			Disk:   sumOf(diskList),
+			DiskIO: sumOf(averageDiskIOList),
+		}
+		peakDemand := demand
+		peakDemand.CPU = sumOf(cpuList)
+		peakDemand.DiskIO = sumOf(diskIOList)
+		utilization := groupUtilization(demand, perNode, group.NoOfNodes)
+		peakUtilization := groupUtilization(peakDemand, perNode, group.NoOfNodes)
+		degradedUtilization := groupUtilization(peakDemand, perNode, nodes)
+		warnings = append(warnings, targetUtilizationWarnings(group, degradedUtilization, targets)...)
+		clusterTotal = clusterTotal.Add(groupTotal)
+
 		// Store the result for this service group
 		serviceGroupResults = append(serviceGroupResults, models.ServiceGroupResult{
-			Services:        group.Services,
-			Nodes:           group.NoOfNodes,
-			EstimatedRAM:    int64(totalRAM),
-			EstimatedCPU:    int64(totalCPU),
-			DiskType:        group.DiskType,
-			EstimatedDisk:   int64(totalDisk),
-			EstimatedDiskIO: int64(totalDiskIO),
+			Services:             group.Services,
+			Nodes:                group.NoOfNodes,
+			EstimatedRAM:         int64(totalRAM),
+			EstimatedCPU:         int64(totalCPU),
+			DiskType:             group.DiskType,
+			EstimatedDisk:        int64(totalDisk),
+			EstimatedDiskIO:      int64(totalDiskIO),
+			WriteLatencyMs:       writeLatency,
+			CompactionThroughput: compactionThroughput,
+			ServiceQuotas:        serviceQuotas,
+			OSReservedRAM:        services.Round(totalRAM*osMemoryReserved, 2),
+			PerNode:              perNode,
+			Total:                groupTotal,
+			FailureTolerance:     group.FailureTolerance,
+			NodesTolerated:       toleratedNodes(group),
+			TargetUtilization:    targets,
+			Utilization:          utilization,
+			PeakUtilization:      peakUtilization,
+			DegradedUtilization:  degradedUtilization,
 		})
 	}
 
@@ -113,11 +174,68 @@ func EstimateResources(request models.ComputeRequest) models.ComputeResponse {
 		ServiceGroups:  int64(len(request.ServiceGroups)),
 		Services:       servicesAll,
 		WorkloadType:   request.WorkloadNature,
+		Total:          clusterTotal,
 	}
 
+	// Warn about the guardrails the request exceeds
+	warnings = append(warnings, durabilityWarnings(request.Dataset, dataNodes, dataGroup)...)
+	warnings = append(warnings, compactionWarnings(request.Dataset, request.Workload, dataNodes, dataGroup)...)
+	warnings = append(warnings, purgeIntervalWarnings(request, dataGroup)...)
+	warnings = append(warnings, analyticsSourceWarnings(request.Dataset, request.ServiceGroups)...)
+
+	// Check the request and the estimate against the Capella guardrails
+	guardrailWarnings, blocked := evaluateGuardrails(guardrails, request, serviceGroupResults, request.ServiceGroups, dataRAM, dataCPU)
+	warnings = append(warnings, guardrailWarnings...)
+
 	// Return the results for all the service groups
 	return models.ComputeResponse{
 		Summary:              summary,
 		ServiceGroupsResults: serviceGroupResults,
+		WorkingSet:           workingSet,
+		Units:                models.DefaultResultUnits,
+		Tombstones:           tombstones,
+		InterZoneTraffic:     zoneTraffic,
+		WorkloadProfile:      profile.result(),
+		Warnings:             warnings,
+		Blocked:              blocked,
+	}
+}
+
+// residentRatio returns the resident ratio Data is sized for, the recommendation of the access pattern replaces the user's guess
+func residentRatio(request models.ComputeRequest, overhead services.DataOverhead) (int64, *models.WorkingSetResult) {
+	if request.Dataset.AccessPattern.Model == "" {
+		return request.Dataset.ResidentRatio, nil
+	}
+	recommendation := services.RecommendResidentRatio(request.Dataset, request.Workload, overhead)
+	return recommendation.RecommendedResidentRatio, &recommendation
+}
+
+// dataServiceOverhead returns the load other components of the request add to the Data service
+func dataServiceOverhead(request models.ComputeRequest) services.DataOverhead {
+	return services.SyncGatewayDataOverhead(request.Dataset, request.Workload, request.SyncGateway).
+		Add(services.TransactionDataOverhead(request.Dataset, request.Workload)).
+		Add(services.DCPDataOverhead(request.DCPConsumers))
+}
+
+// estimateService calls the calculator of the service
+func estimateService(service string, request models.ComputeRequest, overhead services.DataOverhead) (ram, cpu, disk, diskIO float64) {
+	switch service {
+	case "data":
+		return services.EstimateResourcesForData(request.Dataset, request.Workload, overhead)
+	case "index":
+		return services.EstimateResourcesForIndex(request.Dataset, request.Workload)
+	case "query":
+		return services.EstimateResourcesForQuery(request.Dataset, request.Workload)
+	case "search":
+		return services.EstimateResourcesForSearch(request.Dataset, request.Workload)
+	case "eventing":
+		return services.EstimateResourcesForEventing(request.Dataset, request.Workload)
+	case "analytics":
+		return services.EstimateResourcesForAnalytics(request.Dataset, request.Workload)
+	case "backup":
+		return services.EstimateResourcesForBackup(request.Dataset, request.Workload, request.Backup)
+	case "sync_gateway":
+		return services.EstimateResourcesForSyncGateway(request.Dataset, request.Workload, request.SyncGateway)
 	}
+	return 0, 0, 0, 0
 }
@@ -4,6 +4,13 @@ import "workload-estimator-poc/models"
 
 // applyDefaults assigns default values for dataset, workload, and service groups based on workloadNature
 func applyDefaults(request *models.ComputeRequest) {
+	applyWorkloadPreset(request)
+	applyBackupDefaults(&request.Backup)
+	applyDCPConsumerDefaults(&request.DCPConsumers, request.ServiceGroups)
+}
+
+// applyWorkloadPreset presets the service groups, dataset and workload of the workload nature
+func applyWorkloadPreset(request *models.ComputeRequest) {
 	// Preserve user-provided values for noOfDocuments and averageDocumentSize
 	noOfDocuments := request.Dataset.NoOfDocuments
 	averageDocumentSize := request.Dataset.AverageDocumentSize
@@ -72,3 +79,37 @@ func applyDefaults(request *models.ComputeRequest) {
 		// Do nothing, use user-provided values
 	}
 }
+
+// applyDCPConsumerDefaults opens a single DCP consumer for every service of the groups that has no consumer count
+func applyDCPConsumerDefaults(consumers *models.DCPConsumers, groups []models.ServiceGroup) {
+	for _, group := range groups {
+		for _, service := range group.Services {
+			switch service {
+			case "index":
+				consumers.Indexes = max(consumers.Indexes, 1)
+			case "search":
+				consumers.SearchIndexes = max(consumers.SearchIndexes, 1)
+			case "eventing":
+				consumers.EventingFunctions = max(consumers.EventingFunctions, 1)
+			case "analytics":
+				consumers.AnalyticsLinks = max(consumers.AnalyticsLinks, 1)
+			}
+		}
+	}
+}
+
+// applyBackupDefaults fills in the backup schedule fields that were not provided
+func applyBackupDefaults(backup *models.BackupPolicy) {
+	if backup.FullBackupIntervalDays <= 0 {
+		backup.FullBackupIntervalDays = 7
+	}
+	if backup.IncrementalBackupIntervalHours <= 0 {
+		backup.IncrementalBackupIntervalHours = 24
+	}
+	if backup.RetentionDays <= 0 {
+		backup.RetentionDays = 30
+	}
+	if backup.BackupWindowHours <= 0 {
+		backup.BackupWindowHours = 4
+	}
+}
@@ -0,0 +1,97 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
+)
+
+// Failure tolerances of a service group
+const (
+	FailureToleranceNone = "none"
+	FailureToleranceN1   = "n+1" // the group survives the loss of a node
+	FailureToleranceN2   = "n+2" // the group survives the loss of two nodes
+	FailureToleranceAZ   = "az"  // the group survives the loss of an availability zone
+)
+
+// defaultAvailabilityZones is the number of availability zones a group tolerating the loss of a zone is spread over
+const defaultAvailabilityZones = 3
+
+// availabilityZones returns the availability zones (server groups) the group is spread over
+func availabilityZones(group models.ServiceGroup) int64 {
+	if group.AvailabilityZones > 0 {
+		return group.AvailabilityZones
+	}
+	if strings.ToLower(group.FailureTolerance) == FailureToleranceAZ {
+		return defaultAvailabilityZones
+	}
+	return 1
+}
+
+// toleratedNodes returns the nodes the group may lose while still meeting its demand
+func toleratedNodes(group models.ServiceGroup) int64 {
+	switch strings.ToLower(group.FailureTolerance) {
+	case FailureToleranceN1:
+		return 1
+	case FailureToleranceN2:
+		return 2
+	case FailureToleranceAZ:
+		// the zone holding the most nodes is lost
+		return int64(math.Ceil(float64(group.NoOfNodes) / float64(availabilityZones(group))))
+	}
+	return 0
+}
+
+// sizingNodes returns the nodes left after the tolerated failure, the demand of the group is spread over them
+func sizingNodes(group models.ServiceGroup) int64 {
+	if group.NoOfNodes <= 0 {
+		return group.NoOfNodes
+	}
+	return max(group.NoOfNodes-toleratedNodes(group), 1)
+}
+
+// groupUtilization returns the share of the resources of the given nodes the demand uses (In %)
+func groupUtilization(demand models.Utilization, perNode models.Resources, nodes int64) models.Utilization {
+	share := func(demand float64, capacity int64) float64 {
+		if capacity <= 0 || nodes <= 0 {
+			return 0
+		}
+		return services.Round(demand*100/(float64(capacity)*float64(nodes)), 1)
+	}
+	return models.Utilization{
+		RAM:    share(demand.RAM, perNode.RAM),
+		CPU:    share(demand.CPU, perNode.CPU),
+		Disk:   share(demand.Disk, perNode.Disk),
+		DiskIO: share(demand.DiskIO, perNode.DiskIO),
+	}
+}
+
+// failoverWarnings returns a warning when the failure tolerance of the group is unknown, it is then sized without
+// failover headroom, or when the group has too few nodes to survive its tolerated failure
+func failoverWarnings(group models.ServiceGroup) []models.Warning {
+	switch strings.ToLower(group.FailureTolerance) {
+	case "", FailureToleranceNone, FailureToleranceN1, FailureToleranceN2, FailureToleranceAZ:
+	default:
+		return []models.Warning{{
+			Code:        "FAILURE_TOLERANCE_UNKNOWN",
+			Severity:    models.SeverityWarning,
+			Group:       groupName(group),
+			Message:     fmt.Sprintf("unknown failure tolerance %q, the group is sized without failover headroom", group.FailureTolerance),
+			Remediation: fmt.Sprintf("use %s, %s, %s or %s", FailureToleranceNone, FailureToleranceN1, FailureToleranceN2, FailureToleranceAZ),
+		}}
+	}
+
+	tolerated := toleratedNodes(group)
+	if tolerated == 0 || group.NoOfNodes > tolerated {
+		return nil
+	}
+	return []models.Warning{{
+		Code:        "FAILURE_TOLERANCE_NODES",
+		Severity:    models.SeverityError,
+		Group:       groupName(group),
+		Message:     fmt.Sprintf("%d nodes cannot survive the loss of %d nodes required by failure tolerance %s", group.NoOfNodes, tolerated, group.FailureTolerance),
+		Remediation: fmt.Sprintf("run the group on at least %d nodes", tolerated+1),
+	}}
+}
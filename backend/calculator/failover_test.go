@@ -0,0 +1,41 @@
+package calculator
+
+import (
+	"testing"
+	"workload-estimator-poc/models"
+)
+
+func TestToleratedNodes(t *testing.T) {
+	tests := []struct {
+		group       models.ServiceGroup
+		want        int64
+		wantSizing  int64
+		wantWarning string
+	}{
+		{group: models.ServiceGroup{NoOfNodes: 3}, want: 0, wantSizing: 3},
+		{group: models.ServiceGroup{NoOfNodes: 3, FailureTolerance: "none"}, want: 0, wantSizing: 3},
+		{group: models.ServiceGroup{NoOfNodes: 3, FailureTolerance: "n+1"}, want: 1, wantSizing: 2},
+		{group: models.ServiceGroup{NoOfNodes: 4, FailureTolerance: "N+2"}, want: 2, wantSizing: 2},
+		{group: models.ServiceGroup{NoOfNodes: 7, FailureTolerance: "az"}, want: 3, wantSizing: 4},
+		{group: models.ServiceGroup{NoOfNodes: 6, FailureTolerance: "az", AvailabilityZones: 2}, want: 3, wantSizing: 3},
+		{group: models.ServiceGroup{NoOfNodes: 2, FailureTolerance: "n+2"}, want: 2, wantSizing: 1, wantWarning: "FAILURE_TOLERANCE_NODES"},
+		{group: models.ServiceGroup{NoOfNodes: 5, FailureTolerance: "n+3"}, want: 0, wantSizing: 5, wantWarning: "FAILURE_TOLERANCE_UNKNOWN"},
+		{group: models.ServiceGroup{NoOfNodes: 5, FailureTolerance: "zone"}, want: 0, wantSizing: 5, wantWarning: "FAILURE_TOLERANCE_UNKNOWN"},
+	}
+	for _, test := range tests {
+		group := test.group
+		if got := toleratedNodes(group); got != test.want {
+			t.Errorf("toleratedNodes(%d nodes, %q) = %d, want %d", group.NoOfNodes, group.FailureTolerance, got, test.want)
+		}
+		if got := sizingNodes(group); got != test.wantSizing {
+			t.Errorf("sizingNodes(%d nodes, %q) = %d, want %d", group.NoOfNodes, group.FailureTolerance, got, test.wantSizing)
+		}
+		var code string
+		if warnings := failoverWarnings(group); len(warnings) > 0 {
+			code = warnings[0].Code
+		}
+		if code != test.wantWarning {
+			t.Errorf("failoverWarnings(%d nodes, %q) = %q, want %q", group.NoOfNodes, group.FailureTolerance, code, test.wantWarning)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package calculator
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
+)
+
+// Guardrail actions
+const (
+	GuardrailBlock = "block"
+	GuardrailWarn  = "warn"
+)
+
+//go:embed guardrails.json
+var guardrailsJSON []byte
+
+// guardrails is the rule set evaluated against every estimate
+var guardrails = mustLoadGuardrails(guardrailsJSON)
+
+// mustLoadGuardrails parses the embedded rule set, an invalid rule set is a build error
+func mustLoadGuardrails(data []byte) models.GuardrailRuleSet {
+	var ruleSet models.GuardrailRuleSet
+	if err := json.Unmarshal(data, &ruleSet); err != nil {
+		panic(fmt.Sprintf("invalid guardrail rule set: %v", err))
+	}
+	return ruleSet
+}
+
+// Guardrails returns the guardrail rule set the estimates are checked against
+func Guardrails() models.GuardrailRuleSet {
+	return guardrails
+}
+
+// guardrailScope holds the metrics and conditions a rule is evaluated on
+type guardrailScope struct {
+	group      string
+	services   []string
+	metrics    map[string]float64
+	conditions map[string]string
+}
+
+// evaluateGuardrails checks the request and its estimate against the rule set, blocked is true when a blocking rule fails.
+// dataCPU is the Data CPU demand of the bucket before the minimum cores are applied.
+func evaluateGuardrails(ruleSet models.GuardrailRuleSet, request models.ComputeRequest, results []models.ServiceGroupResult, groups []models.ServiceGroup, dataRAM, dataCPU float64) (warnings []models.Warning, blocked bool) {
+	// Step 1: Cluster metrics
+	conditions := map[string]string{"storage_engine": "couchstore"}
+	_, collections := services.CollectionCounts(request.Dataset)
+	cluster := guardrailScope{
+		metrics: map[string]float64{
+			"collections": float64(collections),
+			"data_copies": float64(services.BucketReplicas(request.Dataset) + 1),
+		},
+		conditions: conditions,
+	}
+	var dataNodes int64
+	for i, result := range results {
+		cluster.services = append(cluster.services, result.Services...)
+		if hasService(result.Services, "data") {
+			dataNodes += result.Nodes
+			cluster.group = groupName(groups[i])
+		}
+	}
+	if dataNodes > 0 {
+		cluster.metrics["data_nodes"] = float64(dataNodes)
+		cluster.metrics["resident_ratio"] = float64(request.Dataset.ResidentRatio)
+	}
+	if dataRAM > 0 {
+		cluster.metrics["collections_per_gb_quota"] = float64(collections) / dataRAM
+	}
+
+	// Step 2: Group metrics, taken from the quotas and the demand of the Data service
+	var groupScopes []guardrailScope
+	for i, result := range results {
+		scope := guardrailScope{group: groupName(groups[i]), services: result.Services, metrics: map[string]float64{}, conditions: conditions}
+		for _, quota := range result.ServiceQuotas {
+			if quota.Service != "data" {
+				continue
+			}
+			if quota.RAM > 0 {
+				scope.metrics["data_disk_to_ram_ratio"] = quota.Disk / quota.RAM
+			}
+			// a single bucket is sized, the quota already holds the minimum cores of a bucket
+			scope.metrics["data_cpu_per_bucket"] = dataCPU
+		}
+		groupScopes = append(groupScopes, scope)
+	}
+
+	// Step 3: Evaluate every rule on its scopes
+	for _, rule := range ruleSet.Rules {
+		scopes := []guardrailScope{cluster}
+		if rule.Scope == "group" {
+			scopes = groupScopes
+		}
+		for _, scope := range scopes {
+			// rules of a service apply to the groups, or the cluster, running it
+			if rule.Service != "" && !hasService(scope.services, rule.Service) {
+				continue
+			}
+			message, failed := evaluateGuardrailRule(rule, scope)
+			if !failed {
+				continue
+			}
+			severity := models.SeverityWarning
+			if rule.Action == GuardrailBlock {
+				severity = models.SeverityError
+				blocked = true
+			}
+			warnings = append(warnings, models.Warning{
+				Code:        rule.ID,
+				Severity:    severity,
+				Group:       scope.group,
+				Service:     rule.Service,
+				Message:     message,
+				Remediation: rule.Remediation,
+			})
+		}
+	}
+	return warnings, blocked
+}
+
+// evaluateGuardrailRule returns whether the scope fails the rule and why, rules whose conditions or metrics do not apply pass
+func evaluateGuardrailRule(rule models.GuardrailRule, scope guardrailScope) (message string, failed bool) {
+	for key, value := range rule.When {
+		if !strings.EqualFold(scope.conditions[key], value) {
+			return "", false
+		}
+	}
+
+	if rule.Operator == "in" {
+		for _, allowed := range rule.AllowedServiceSets {
+			if sameServices(scope.services, allowed) {
+				return "", false
+			}
+		}
+		return fmt.Sprintf("%s, %s is not one of them", rule.Description, strings.Join(scope.services, "+")), true
+	}
+
+	value, ok := scope.metrics[rule.Metric]
+	if !ok {
+		return "", false
+	}
+	threshold := rule.Threshold
+	if rule.ThresholdMetric != "" {
+		if threshold, ok = scope.metrics[rule.ThresholdMetric]; !ok {
+			return "", false
+		}
+	}
+
+	var passed bool
+	switch rule.Operator {
+	case "<":
+		passed = value < threshold
+	case "<=":
+		passed = value <= threshold
+	case ">":
+		passed = value > threshold
+	case ">=":
+		passed = value >= threshold
+	default:
+		return fmt.Sprintf("%s, unknown operator %q", rule.Description, rule.Operator), true
+	}
+	if passed {
+		return "", false
+	}
+	return fmt.Sprintf("%s, %s is %s", rule.Description, strings.ReplaceAll(rule.Metric, "_", " "), formatMetric(value)), true
+}
+
+// hasService reports whether the service is one of the services
+func hasService(services []string, service string) bool {
+	for _, s := range services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// sameServices reports whether both lists hold the same services in any order
+func sameServices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string(nil), a...)
+	sortedB := append([]string(nil), b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatMetric formats a metric value with at most two decimals
+func formatMetric(value float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+}
@@ -0,0 +1,82 @@
+package calculator
+
+import (
+	"testing"
+	"workload-estimator-poc/models"
+)
+
+func TestEvaluateGuardrailRule(t *testing.T) {
+	scope := guardrailScope{
+		services:   []string{"data", "index"},
+		metrics:    map[string]float64{"data_nodes": 3, "data_copies": 2},
+		conditions: map[string]string{"storage_engine": "couchstore"},
+	}
+	tests := []struct {
+		name       string
+		rule       models.GuardrailRule
+		wantFailed bool
+	}{
+		{name: "below the maximum", rule: models.GuardrailRule{Metric: "data_nodes", Operator: "<=", Threshold: 3}},
+		{name: "above the maximum", rule: models.GuardrailRule{Metric: "data_nodes", Operator: "<", Threshold: 3}, wantFailed: true},
+		{name: "at the minimum", rule: models.GuardrailRule{Metric: "data_nodes", Operator: ">=", Threshold: 3}},
+		{name: "below the minimum", rule: models.GuardrailRule{Metric: "data_nodes", Operator: ">", Threshold: 3}, wantFailed: true},
+		{name: "missing metric passes", rule: models.GuardrailRule{Metric: "collections", Operator: "<", Threshold: 0}},
+		{name: "condition does not apply", rule: models.GuardrailRule{When: map[string]string{"storage_engine": "magma"}, Metric: "data_nodes", Operator: "<", Threshold: 1}},
+		{name: "condition matches regardless of case", rule: models.GuardrailRule{When: map[string]string{"storage_engine": "Couchstore"}, Metric: "data_nodes", Operator: "<", Threshold: 1}, wantFailed: true},
+		{name: "threshold from another metric", rule: models.GuardrailRule{Metric: "data_nodes", Operator: ">=", ThresholdMetric: "data_copies"}},
+		{name: "threshold metric exceeded", rule: models.GuardrailRule{Metric: "data_copies", Operator: ">=", ThresholdMetric: "data_nodes"}, wantFailed: true},
+		{name: "missing threshold metric passes", rule: models.GuardrailRule{Metric: "data_nodes", Operator: ">=", ThresholdMetric: "collections"}},
+		{name: "allowed service set in any order", rule: models.GuardrailRule{Operator: "in", AllowedServiceSets: [][]string{{"data"}, {"index", "data"}}}},
+		{name: "service set not allowed", rule: models.GuardrailRule{Operator: "in", AllowedServiceSets: [][]string{{"data"}, {"data", "index", "query"}}}, wantFailed: true},
+		{name: "unknown operator fails", rule: models.GuardrailRule{Metric: "data_nodes", Operator: "=="}, wantFailed: true},
+	}
+	for _, test := range tests {
+		message, failed := evaluateGuardrailRule(test.rule, scope)
+		if failed != test.wantFailed {
+			t.Errorf("%s: failed = %v (%q), want %v", test.name, failed, message, test.wantFailed)
+		}
+		if failed && message == "" {
+			t.Errorf("%s: failed without a message", test.name)
+		}
+	}
+}
+
+func TestEvaluateGuardrailsDataCPU(t *testing.T) {
+	ruleSet := models.GuardrailRuleSet{Rules: []models.GuardrailRule{{
+		ID:        "CPU_PER_BUCKET",
+		Scope:     "group",
+		Service:   "data",
+		Metric:    "data_cpu_per_bucket",
+		Operator:  ">=",
+		Threshold: 0.2,
+		Action:    GuardrailBlock,
+	}}}
+	groups := []models.ServiceGroup{
+		{Name: "data", Services: []string{"data"}, NoOfNodes: 3},
+		{Name: "query", Services: []string{"query"}, NoOfNodes: 2},
+	}
+	results := []models.ServiceGroupResult{
+		{Services: groups[0].Services, Nodes: 3, ServiceQuotas: []models.ServiceQuota{{Service: "data", RAM: 8, CPU: 4, Disk: 100}}},
+		{Services: groups[1].Services, Nodes: 2},
+	}
+
+	tests := []struct {
+		dataCPU     float64
+		wantBlocked bool
+	}{
+		{dataCPU: 0.1, wantBlocked: true},
+		{dataCPU: 0.2},
+		{dataCPU: 2.5},
+	}
+	for _, test := range tests {
+		warnings, blocked := evaluateGuardrails(ruleSet, models.ComputeRequest{}, results, groups, 8, test.dataCPU)
+		if blocked != test.wantBlocked {
+			t.Errorf("Data CPU demand %v: blocked = %v, want %v", test.dataCPU, blocked, test.wantBlocked)
+		}
+		for _, warning := range warnings {
+			if warning.Group != "data" || warning.Severity != models.SeverityError {
+				t.Errorf("Data CPU demand %v: unexpected warning %+v", test.dataCPU, warning)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package calculator
+
+import (
+	"math"
+	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
+)
+
+// Windows over which a burst shorter than the window is spread when sizing, the burst's work waits in a queue meanwhile.
+// Requests queue for CPU until the clients' timeouts, a few minutes at most at the cost of latency. The disk write queue
+// holds the burst's mutations in memory and the flusher drains it after the burst.
+const (
+	cpuBurstWindowMinutes    = 5.0
+	diskIOBurstWindowMinutes = 15.0
+)
+
+// profile holds the peak of the workload relative to its average rates, and the peaks CPU and disk IO are sized for
+type profile struct {
+	peakFactor       float64
+	cpuPeakFactor    float64
+	diskIOPeakFactor float64
+	peakHour         *int64
+	burstMinutes     float64
+	set              bool
+}
+
+// workloadProfile resolves the peak of the workload profile, without a profile the workload runs flat at its rates.
+// The profile was validated when the request was decoded.
+func workloadProfile(requested *models.WorkloadProfile) profile {
+	resolved := profile{peakFactor: 1, cpuPeakFactor: 1, diskIOPeakFactor: 1}
+	if requested == nil {
+		return resolved
+	}
+
+	// Step 1: Hourly factors are taken relative to their mean, the rates of the workload being the average.
+	// The peak lasts a whole hour, longer than any window.
+	if len(requested.HourlyFactors) > 0 {
+		var sum, highest float64
+		var highestHour int64
+		for hour, factor := range requested.HourlyFactors {
+			sum += factor
+			if factor > highest {
+				highest, highestHour = factor, int64(hour)
+			}
+		}
+		if sum == 0 {
+			return resolved
+		}
+		peakFactor := highest / (sum / float64(len(requested.HourlyFactors)))
+		return profile{
+			peakFactor:       peakFactor,
+			cpuPeakFactor:    peakFactor,
+			diskIOPeakFactor: peakFactor,
+			peakHour:         &highestHour,
+			burstMinutes:     60,
+			set:              true,
+		}
+	}
+
+	// Step 2: A peak multiplier holds for the burst, unset bursts are sustained
+	if requested.PeakMultiplier <= 1 {
+		return resolved
+	}
+	resolved.set = true
+	resolved.peakFactor = requested.PeakMultiplier
+	resolved.burstMinutes = requested.BurstMinutes
+	resolved.cpuPeakFactor = burstPeakFactor(requested.PeakMultiplier, requested.BurstMinutes, cpuBurstWindowMinutes)
+	resolved.diskIOPeakFactor = burstPeakFactor(requested.PeakMultiplier, requested.BurstMinutes, diskIOBurstWindowMinutes)
+	return resolved
+}
+
+// burstPeakFactor returns the peak a resource is sized for, a burst shorter than the window is spread over the window
+func burstPeakFactor(peakMultiplier, burstMinutes, windowMinutes float64) float64 {
+	if burstMinutes <= 0 || burstMinutes >= windowMinutes {
+		return peakMultiplier
+	}
+	return 1 + (peakMultiplier-1)*burstMinutes/windowMinutes
+}
+
+// result reports the profile the estimate is sized for, nil without a profile
+func (p profile) result() *models.ProfileResult {
+	if !p.set {
+		return nil
+	}
+	return &models.ProfileResult{
+		PeakFactor:       services.Round(p.peakFactor, 2),
+		CPUPeakFactor:    services.Round(p.cpuPeakFactor, 2),
+		DiskIOPeakFactor: services.Round(p.diskIOPeakFactor, 2),
+		PeakHour:         p.peakHour,
+		BurstMinutes:     p.burstMinutes,
+	}
+}
+
+// scaleWorkload returns the workload with every rate multiplied by the factor
+func scaleWorkload(workload models.Workload, factor float64) models.Workload {
+	scale := func(rate int64) int64 {
+		return int64(math.Round(float64(rate) * factor))
+	}
+	workload.ReadPerSec = scale(workload.ReadPerSec)
+	workload.WritesPerSec = scale(workload.WritesPerSec)
+	workload.DeletesPerSec = scale(workload.DeletesPerSec)
+	workload.SQLQueriesPerSec = scale(workload.SQLQueriesPerSec)
+	workload.AnalyticsConcurrentQueries = scale(workload.AnalyticsConcurrentQueries)
+	workload.AnalyticsIngestionPerSec = scale(workload.AnalyticsIngestionPerSec)
+	workload.TransactionsPerSec = scale(workload.TransactionsPerSec)
+	return workload
+}
@@ -0,0 +1,61 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+	"workload-estimator-poc/models"
+)
+
+func TestBurstPeakFactor(t *testing.T) {
+	tests := []struct {
+		name                  string
+		peakMultiplier, burst float64
+		window                float64
+		want                  float64
+	}{
+		{name: "sustained peak", peakMultiplier: 3, burst: 0, window: cpuBurstWindowMinutes, want: 3},
+		{name: "burst as long as the window", peakMultiplier: 3, burst: 5, window: cpuBurstWindowMinutes, want: 3},
+		{name: "burst longer than the window", peakMultiplier: 3, burst: 30, window: diskIOBurstWindowMinutes, want: 3},
+		{name: "short burst spread over the CPU window", peakMultiplier: 3, burst: 3, window: cpuBurstWindowMinutes, want: 2.2},
+		{name: "short burst spread over the disk IO window", peakMultiplier: 3, burst: 3, window: diskIOBurstWindowMinutes, want: 1.4},
+		{name: "flat workload", peakMultiplier: 1, burst: 1, window: cpuBurstWindowMinutes, want: 1},
+	}
+	for _, test := range tests {
+		if got := burstPeakFactor(test.peakMultiplier, test.burst, test.window); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: burstPeakFactor(%v, %v, %v) = %v, want %v", test.name, test.peakMultiplier, test.burst, test.window, got, test.want)
+		}
+	}
+}
+
+func TestWorkloadProfile(t *testing.T) {
+	daily := make([]float64, models.HoursPerDay)
+	for i := range daily {
+		daily[i] = 1
+	}
+	daily[20] = 2.5
+
+	tests := []struct {
+		name                string
+		profile             *models.WorkloadProfile
+		wantSet             bool
+		wantCPU, wantDiskIO float64
+		wantPeakHour        int64
+	}{
+		{name: "no profile", wantCPU: 1, wantDiskIO: 1},
+		{name: "multiplier of at most 1", profile: &models.WorkloadProfile{PeakMultiplier: 0.5}, wantCPU: 1, wantDiskIO: 1},
+		{name: "sustained multiplier", profile: &models.WorkloadProfile{PeakMultiplier: 2}, wantSet: true, wantCPU: 2, wantDiskIO: 2},
+		{name: "short burst", profile: &models.WorkloadProfile{PeakMultiplier: 3, BurstMinutes: 3}, wantSet: true, wantCPU: 2.2, wantDiskIO: 1.4},
+		{name: "hourly factors relative to their mean", profile: &models.WorkloadProfile{HourlyFactors: daily}, wantSet: true, wantCPU: 2.5 / (25.5 / 24), wantDiskIO: 2.5 / (25.5 / 24), wantPeakHour: 20},
+		{name: "hourly factors win over the multiplier", profile: &models.WorkloadProfile{HourlyFactors: daily, PeakMultiplier: 10, BurstMinutes: 1}, wantSet: true, wantCPU: 2.5 / (25.5 / 24), wantDiskIO: 2.5 / (25.5 / 24), wantPeakHour: 20},
+		{name: "all hourly factors zero", profile: &models.WorkloadProfile{HourlyFactors: make([]float64, models.HoursPerDay)}, wantCPU: 1, wantDiskIO: 1},
+	}
+	for _, test := range tests {
+		got := workloadProfile(test.profile)
+		if got.set != test.wantSet || math.Abs(got.cpuPeakFactor-test.wantCPU) > 1e-9 || math.Abs(got.diskIOPeakFactor-test.wantDiskIO) > 1e-9 {
+			t.Errorf("%s: set %v, CPU peak %v, disk IO peak %v, want %v, %v, %v", test.name, got.set, got.cpuPeakFactor, got.diskIOPeakFactor, test.wantSet, test.wantCPU, test.wantDiskIO)
+		}
+		if test.wantPeakHour != 0 && (got.peakHour == nil || *got.peakHour != test.wantPeakHour) {
+			t.Errorf("%s: peak hour %v, want %d", test.name, got.peakHour, test.wantPeakHour)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package calculator
+
+import (
+	"math"
+	"strconv"
+	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
+)
+
+// Projection limits
+const (
+	defaultProjectionMonths = 12
+	maxProjectionMonths     = 60
+)
+
+// ProjectGrowth estimates the request month by month as its dataset and workload grow.
+// The groups keep the nodes of the previous month, a group exceeding its per-node limits is scaled up.
+func ProjectGrowth(request models.ProjectionRequest) models.ProjectionResponse {
+	months := request.Growth.Months
+	if months <= 0 {
+		months = defaultProjectionMonths
+	}
+	months = min(months, maxProjectionMonths)
+
+	// Step 1: Apply the preset of the workload nature once, every month keeps its service groups.
+	// The groups are named by their index while estimating, warnings then tell apart groups sharing a name.
+	base := request.ComputeRequest
+	applyDefaults(&base)
+	base.WorkloadNature = "override"
+	names := make([]string, len(base.ServiceGroups))
+	groups := append([]models.ServiceGroup(nil), base.ServiceGroups...)
+	for i := range groups {
+		names[i] = groupName(groups[i])
+		groups[i].Name = strconv.Itoa(i)
+	}
+
+	var response models.ProjectionResponse
+	unresolved := map[int]bool{}
+	for month := int64(0); month <= months; month++ {
+		// Step 2: Grow the dataset and the workload
+		monthly, opsFactor := grownRequest(base, request.Growth, month)
+
+		// Step 3: Estimate on the nodes of the previous month, the limits it exceeds call for a scale-up
+		monthly.ServiceGroups = groups
+		estimate := EstimateResources(monthly)
+		reasons := scalingReasons(estimate)
+		if len(reasons) > 0 {
+			scaled := append([]models.ServiceGroup(nil), groups...)
+			estimate = estimateTopology(monthly, scaled)
+
+			// groups still exceeding their limits after the node additions are reported as unresolved,
+			// once when they become unresolved
+			remaining := scalingReasons(estimate)
+			for i := range scaled {
+				newlyUnresolved := len(remaining[i]) > 0 && !unresolved[i]
+				unresolved[i] = len(remaining[i]) > 0
+				if scaled[i].NoOfNodes == groups[i].NoOfNodes && !newlyUnresolved {
+					continue
+				}
+				scaleUp := models.ScaleUp{
+					Month:     month,
+					Group:     names[i],
+					FromNodes: groups[i].NoOfNodes,
+					ToNodes:   scaled[i].NoOfNodes,
+					Reasons:   reasons[i],
+				}
+				if len(remaining[i]) > 0 {
+					scaleUp.Unresolved = true
+					scaleUp.Reasons = remaining[i]
+				}
+				response.ScaleUps = append(response.ScaleUps, scaleUp)
+			}
+			groups = scaled
+		} else {
+			clear(unresolved)
+		}
+
+		// Step 4: Record the nodes every group needs this month
+		projected := models.ProjectionMonth{
+			Month:     month,
+			Documents: monthly.Dataset.NoOfDocuments,
+			OpsFactor: services.Round(opsFactor, 3),
+			Total:     estimate.Summary.Total,
+			Blocked:   estimate.Blocked,
+		}
+		for i, result := range estimate.ServiceGroupsResults {
+			projected.Groups = append(projected.Groups, models.ProjectedGroup{
+				Name:        names[i],
+				Nodes:       result.Nodes,
+				PerNode:     result.PerNode,
+				Total:       result.Total,
+				Utilization: result.Utilization,
+			})
+		}
+		response.Timeline = append(response.Timeline, projected)
+	}
+	return response
+}
+
+// scalingReasons returns the per-node limits every group of the estimate exceeds, by the index the group is named by
+func scalingReasons(estimate models.ComputeResponse) map[int][]string {
+	reasons := map[int][]string{}
+	for _, warning := range estimate.Warnings {
+		if !scalingWarning(warning.Code) {
+			continue
+		}
+		if i, err := strconv.Atoi(warning.Group); err == nil {
+			reasons[i] = append(reasons[i], warning.Message)
+		}
+	}
+	return reasons
+}
+
+// grownRequest returns the request after the given months of growth and the factor its workload rates grew by
+func grownRequest(request models.ComputeRequest, growth models.Growth, month int64) (models.ComputeRequest, float64) {
+	// Step 1: Documents grow compounded plus a fixed number every month, up to the retention cap
+	documents := float64(request.Dataset.NoOfDocuments)*math.Pow(1+growth.DocumentGrowthPercent/100, float64(month)) +
+		float64(growth.DocumentsPerMonth*month)
+	if growth.MaxDocuments > 0 {
+		documents = math.Min(documents, math.Max(float64(growth.MaxDocuments), float64(request.Dataset.NoOfDocuments)))
+	}
+	documentFactor := 1.0
+	if request.Dataset.NoOfDocuments > 0 {
+		documentFactor = documents / float64(request.Dataset.NoOfDocuments)
+	}
+
+	dataset := request.Dataset
+	dataset.NoOfDocuments = int64(math.Round(documents))
+	dataset.Collections = append([]models.Collection(nil), request.Dataset.Collections...)
+	for i := range dataset.Collections {
+		dataset.Collections[i].NoOfDocuments = int64(math.Round(float64(dataset.Collections[i].NoOfDocuments) * documentFactor))
+	}
+
+	// Step 2: Every workload rate grows compounded, up to the growth cap
+	opsFactor := math.Pow(1+growth.OpsGrowthPercent/100, float64(month))
+	if growth.MaxOpsGrowthPercent > 0 {
+		opsFactor = math.Min(opsFactor, 1+growth.MaxOpsGrowthPercent/100)
+	}
+
+	request.Dataset = dataset
+	request.Workload = scaleWorkload(request.Workload, opsFactor)
+	return request, opsFactor
+}
@@ -1,12 +1,84 @@
 package calculator
 
 import (
+	"fmt"
 	"math"
 	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
 )
 
-// RAM is split equally among services (excluding query) on capella
-func CalculateRAM(ramList []float64, services []string, nodes int64) float64 {
+// Disk limits per node
+const (
+	minDiskPerNode   = 50    // GB
+	maxDiskPerNode   = 16000 // GB
+	minDiskIOPerNode = 3000  // IOPS
+	maxDiskIOGP3     = 16000 // IOPS
+	maxDiskIOIO2     = 64000 // IOPS
+)
+
+// osMemoryReserved is the share of a node's memory reserved for the operating system
+const osMemoryReserved = 0.2
+
+// Default target utilisation of a node (In %), the service quotas leave the memory reserved for the operating system
+const (
+	defaultTargetRAM    = (1 - osMemoryReserved) * 100
+	defaultTargetCPU    = 100
+	defaultTargetDisk   = 100
+	defaultTargetDiskIO = 100
+)
+
+// targetUtilization resolves the target utilisation of the request, unset targets take the defaults.
+// The memory target cannot eat into the operating system reservation, the other targets are at most 100%.
+func targetUtilization(requested models.Utilization) (models.Utilization, []models.Warning) {
+	var warnings []models.Warning
+	resolve := func(resource string, target, fallback, limit float64) float64 {
+		switch {
+		case target <= 0:
+			return fallback
+		case target > limit:
+			warnings = append(warnings, models.Warning{
+				Code:        "TARGET_UTILIZATION_CLAMPED",
+				Severity:    models.SeverityWarning,
+				Message:     fmt.Sprintf("%s target utilisation of %.0f%% exceeds the maximum of %.0f%%, sized for %.0f%%", resource, target, limit, limit),
+				Remediation: fmt.Sprintf("set the %s target to at most %.0f%%", resource, limit),
+			})
+			return limit
+		}
+		return target
+	}
+	return models.Utilization{
+		RAM:    resolve("RAM", requested.RAM, defaultTargetRAM, defaultTargetRAM),
+		CPU:    resolve("CPU", requested.CPU, defaultTargetCPU, 100),
+		Disk:   resolve("disk", requested.Disk, defaultTargetDisk, 100),
+		DiskIO: resolve("disk IO", requested.DiskIO, defaultTargetDiskIO, 100),
+	}, warnings
+}
+
+// Memory allocations of a service group
+const (
+	AllocationEqual  = "equal"  // every service (excluding query) gets the quota of the most demanding one, as on capella
+	AllocationDemand = "demand" // every service gets the quota of its own demand
+)
+
+// RAM is split equally among services (excluding query) on capella, or by the demand of each service.
+// The quotas fill the node up to the target utilisation (In %).
+func CalculateRAM(ramList []float64, services []string, nodes int64, allocation string, target float64) float64 {
+	var ramHardwareWithoutOS float64
+	for _, quota := range serviceRAMQuotas(ramList, services, nodes, allocation) {
+		ramHardwareWithoutOS += quota
+	}
+
+	var ramHardware float64 = ramHardwareWithoutOS / (target / 100)
+
+	return ramHardware
+}
+
+// serviceRAMQuotas returns the memory quota per node of every service of the group, query has no quota
+func serviceRAMQuotas(ramList []float64, services []string, nodes int64, allocation string) []float64 {
+	quotas := make([]float64, len(ramList))
+	if nodes == 0 {
+		return quotas
+	}
 
 	noOfServicesInGroup := 0
 	for _, service := range services {
@@ -14,11 +86,7 @@ func CalculateRAM(ramList []float64, services []string, nodes int64) float64 {
 			noOfServicesInGroup++
 		}
 	}
-	if noOfServicesInGroup == 0 {
-		noOfServicesInGroup = 1
-	}
 
-	var osMemoryReserved float64 = 0.2
 	var ramAvailable float64 = 0.0
 	for _, ram := range ramList {
 		var ramAvailableForService float64 = ram / float64(nodes)
@@ -26,15 +94,49 @@ func CalculateRAM(ramList []float64, services []string, nodes int64) float64 {
 			ramAvailable = ramAvailableForService
 		}
 	}
-	var ramHardwareWithoutOS float64 = ramAvailable * float64(noOfServicesInGroup)
 
-	var ramHardware float64 = ramHardwareWithoutOS / (1 - osMemoryReserved)
+	// a group of query only still needs the memory of a single quota
+	if noOfServicesInGroup == 0 {
+		if len(quotas) > 0 {
+			quotas[0] = ramAvailable
+		}
+		return quotas
+	}
 
-	return ramHardware
+	for i, service := range services {
+		if service == "query" {
+			continue
+		}
+		if allocation == AllocationDemand {
+			quotas[i] = ramList[i] / float64(nodes)
+		} else {
+			quotas[i] = ramAvailable
+		}
+	}
+	return quotas
+}
+
+// CalculateServiceQuotas breaks the resources per node of a group down by service
+func CalculateServiceQuotas(ramList, cpuList, diskList, diskIOList []float64, groupServices []string, nodes int64, allocation string) []models.ServiceQuota {
+	if nodes == 0 {
+		return nil
+	}
+	ramQuotas := serviceRAMQuotas(ramList, groupServices, nodes, allocation)
+	quotas := make([]models.ServiceQuota, 0, len(groupServices))
+	for i, service := range groupServices {
+		quotas = append(quotas, models.ServiceQuota{
+			Service: service,
+			RAM:     services.Round(ramQuotas[i], 2),
+			CPU:     services.Round(cpuList[i]/float64(nodes), 2),
+			Disk:    services.Round(diskList[i]/float64(nodes), 2),
+			DiskIO:  services.Round(diskIOList[i]/float64(nodes), 2),
+		})
+	}
+	return quotas
 }
 
-// CalculateCPU takes a list of CPU values and calculates the total CPU hardware required per node
-func CalculateCPU(cpuList []float64, nodes int64) float64 {
+// CalculateCPU takes a list of CPU values and calculates the total CPU hardware required per node to stay under the target utilisation (In %)
+func CalculateCPU(cpuList []float64, nodes int64, target float64) float64 {
 	if nodes == 0 {
 		return 0
 	}
@@ -42,12 +144,12 @@ func CalculateCPU(cpuList []float64, nodes int64) float64 {
 	for _, cpu := range cpuList {
 		totalCPU += cpu
 	}
-	totalCPU /= float64(nodes)
+	totalCPU /= float64(nodes) * target / 100
 	return math.Ceil(totalCPU)
 }
 
-// CalculateDisk takes a list of Disk values and calculates the total Disk storage based on disk type
-func CalculateDisk(diskList []float64, nodes int64) float64 {
+// CalculateDisk takes a list of Disk values and calculates the total Disk storage to stay under the target utilisation (In %)
+func CalculateDisk(diskList []float64, nodes int64, target float64) float64 {
 	if nodes == 0 {
 		return 0
 	}
@@ -55,19 +157,19 @@ func CalculateDisk(diskList []float64, nodes int64) float64 {
 	for _, disk := range diskList {
 		totalDisk += disk
 	}
-	totalDisk /= float64(nodes)
+	totalDisk /= float64(nodes) * target / 100
 	totalDisk = math.Ceil(totalDisk)
-	if totalDisk < 50 {
-		totalDisk = 50
+	if totalDisk < minDiskPerNode {
+		totalDisk = minDiskPerNode
 	}
-	if totalDisk > 16000 {
-		totalDisk = 16000
+	if totalDisk > maxDiskPerNode {
+		totalDisk = maxDiskPerNode
 	}
 	return totalDisk
 }
 
-// CalculateDiskIO takes a list of Disk I/O values and calculates the total Disk I/O
-func CalculateDiskIO(diskIOList []float64, nodes int64, diskType string) float64 {
+// CalculateDiskIO takes a list of Disk I/O values and calculates the total Disk I/O to stay under the target utilisation (In %)
+func CalculateDiskIO(diskIOList []float64, nodes int64, diskType string, target float64) float64 {
 	if nodes == 0 {
 		return 0
 	}
@@ -75,20 +177,20 @@ func CalculateDiskIO(diskIOList []float64, nodes int64, diskType string) float64
 	for _, diskIO := range diskIOList {
 		totalDiskIO += diskIO
 	}
-	totalDiskIO /= float64(nodes)
+	totalDiskIO /= float64(nodes) * target / 100
 	// Adjust totalDiskSpace based on DiskType
 	switch diskType {
 	case "gp3":
-		if totalDiskIO > 16000 {
-			totalDiskIO = 16000
+		if totalDiskIO > maxDiskIOGP3 {
+			totalDiskIO = maxDiskIOGP3
 		}
 	case "io2":
-		if totalDiskIO > 64000 {
-			totalDiskIO = 64000
+		if totalDiskIO > maxDiskIOIO2 {
+			totalDiskIO = maxDiskIOIO2
 		}
 	}
-	if(totalDiskIO < 3000){
-		totalDiskIO = 3000
+	if(totalDiskIO < minDiskIOPerNode){
+		totalDiskIO = minDiskIOPerNode
 	}
 	return totalDiskIO
 }
@@ -0,0 +1,248 @@
+package calculator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
+
+	"github.com/golang/snappy"
+)
+
+// fieldAccumulator collects the statistics of a single field while the sample is walked
+type fieldAccumulator struct {
+	types       map[string]bool
+	documents   int64
+	totalLength int64
+	maxLength   int64
+}
+
+// sizeClassAccumulator collects the documents of a power of two size class
+type sizeClassAccumulator struct {
+	documents       int64
+	totalSize       int64
+	totalCompressed int64
+}
+
+// AnalyzeSample derives the dataset inputs from a sample of real documents and their keys
+func AnalyzeSample(request models.SampleAnalysisRequest) (models.SampleAnalysisResponse, error) {
+	// Step 1: Parse the documents
+	documents, err := parseSampleDocuments(request.Documents)
+	if err != nil {
+		return models.SampleAnalysisResponse{}, err
+	}
+	if len(documents) == 0 {
+		return models.SampleAnalysisResponse{}, errors.New("sample holds no documents")
+	}
+	if len(request.Keys) > 0 && len(request.Keys) != len(documents) {
+		return models.SampleAnalysisResponse{}, fmt.Errorf("sample holds %d documents but %d keys", len(documents), len(request.Keys))
+	}
+
+	// Step 2: Document sizes and Snappy compression, Couchbase keeps a document uncompressed when compression does not pay off
+	var totalSize, totalCompressed int64
+	sizeClasses := map[int]*sizeClassAccumulator{}
+	fields := map[string]*fieldAccumulator{}
+	for _, document := range documents {
+		size := int64(len(document))
+		compressed := int64(len(snappy.Encode(nil, document)))
+		compressed = min(compressed, size)
+		totalSize += size
+		totalCompressed += compressed
+
+		class := int(math.Ceil(math.Log2(math.Max(float64(size), 1))))
+		if sizeClasses[class] == nil {
+			sizeClasses[class] = &sizeClassAccumulator{}
+		}
+		sizeClasses[class].documents++
+		sizeClasses[class].totalSize += size
+		sizeClasses[class].totalCompressed += compressed
+
+		// Step 3: Per-field statistics
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(document))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return models.SampleAnalysisResponse{}, fmt.Errorf("invalid document: %v", err)
+		}
+		present := map[string]bool{}
+		walkSampleFields("", value, fields, present)
+		for path := range present {
+			fields[path].documents++
+		}
+	}
+
+	// Step 4: Key sizes
+	var totalKeySize int64
+	for _, key := range request.Keys {
+		totalKeySize += int64(len(key))
+	}
+
+	sampleSize := int64(len(documents))
+	averageDocumentSize := float64(totalSize) / float64(sampleSize)
+	averageKeySize := 0.0
+	if len(request.Keys) > 0 {
+		averageKeySize = float64(totalKeySize) / float64(len(request.Keys))
+	}
+	compressionRatio := services.Round(1-float64(totalCompressed)/float64(totalSize), 3)
+
+	// Step 5: Field statistics and the text indexed by Search
+	searchFields := map[string]bool{}
+	for _, path := range request.SearchFields {
+		searchFields[path] = true
+	}
+	var fieldStatistics []models.FieldStatistics
+	var averageFieldLength float64
+	for path, field := range fields {
+		var types []string
+		for fieldType := range field.types {
+			types = append(types, fieldType)
+		}
+		sort.Strings(types)
+		averageLength := float64(field.totalLength) / float64(field.documents)
+		fieldStatistics = append(fieldStatistics, models.FieldStatistics{
+			Path:           path,
+			Types:          types,
+			PresentPercent: services.Round(float64(field.documents)*100/float64(sampleSize), 2),
+			AverageLength:  services.Round(averageLength, 2),
+			MaxLength:      field.maxLength,
+		})
+		if searchFields[path] || (len(searchFields) == 0 && field.types["string"]) {
+			// averaged over every document, documents missing the field index no text for it
+			averageFieldLength += float64(field.totalLength) / float64(sampleSize)
+		}
+	}
+	sort.Slice(fieldStatistics, func(i, j int) bool { return fieldStatistics[i].Path < fieldStatistics[j].Path })
+
+	// Step 6: Document size histogram
+	var classKeys []int
+	for class := range sizeClasses {
+		classKeys = append(classKeys, class)
+	}
+	sort.Ints(classKeys)
+	var histogram []models.DocumentSizeBucket
+	for _, class := range classKeys {
+		accumulator := sizeClasses[class]
+		classCompressionRatio := services.Round(1-float64(accumulator.totalCompressed)/float64(accumulator.totalSize), 3)
+		histogram = append(histogram, models.DocumentSizeBucket{
+			Size:             models.ByteSize(services.Round(float64(accumulator.totalSize)/float64(accumulator.documents), 2)),
+			Percent:          services.Round(float64(accumulator.documents)*100/float64(sampleSize), 3),
+			CompressionRatio: &classCompressionRatio,
+		})
+	}
+
+	// Step 7: Ready-to-submit dataset
+	noOfDocuments := request.NoOfDocuments
+	if noOfDocuments <= 0 {
+		noOfDocuments = sampleSize
+	}
+	dataset := models.Dataset{
+		NoOfDocuments:         noOfDocuments,
+		AverageDocumentSize:   models.ByteSize(services.Round(averageDocumentSize, 2)),
+		AverageKeySize:        models.ByteSize(services.Round(averageKeySize, 2)),
+		CompressionRatio:      &compressionRatio,
+		AverageFieldLength:    models.ByteSize(services.Round(averageFieldLength, 2)),
+		DocumentSizeHistogram: histogram,
+	}
+
+	return models.SampleAnalysisResponse{
+		Dataset:             dataset,
+		SampleSize:          sampleSize,
+		AverageDocumentSize: services.Round(averageDocumentSize, 2),
+		AverageKeySize:      services.Round(averageKeySize, 2),
+		CompressionRatio:    compressionRatio,
+		Fields:              fieldStatistics,
+	}, nil
+}
+
+// parseSampleDocuments returns the compacted documents of a JSON array or of a string holding NDJSON
+func parseSampleDocuments(raw json.RawMessage) ([][]byte, error) {
+	var rawDocuments []json.RawMessage
+
+	trimmed := bytes.TrimSpace(raw)
+	switch {
+	case len(trimmed) == 0:
+		return nil, errors.New("documents are missing")
+	case trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &rawDocuments); err != nil {
+			return nil, fmt.Errorf("invalid JSON array of documents: %v", err)
+		}
+	case trimmed[0] == '"':
+		var ndjson string
+		if err := json.Unmarshal(trimmed, &ndjson); err != nil {
+			return nil, fmt.Errorf("invalid NDJSON string: %v", err)
+		}
+		scanner := bufio.NewScanner(strings.NewReader(ndjson))
+		scanner.Buffer(make([]byte, 64*1024), 20*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) > 0 {
+				rawDocuments = append(rawDocuments, append(json.RawMessage(nil), line...))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("invalid NDJSON: %v", err)
+		}
+	default:
+		return nil, errors.New("documents must be a JSON array or a string holding NDJSON")
+	}
+
+	// documents are stored compacted, whitespace of the sample must not count towards their size
+	documents := make([][]byte, 0, len(rawDocuments))
+	for i, rawDocument := range rawDocuments {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, rawDocument); err != nil {
+			return nil, fmt.Errorf("invalid document %d: %v", i+1, err)
+		}
+		documents = append(documents, compacted.Bytes())
+	}
+	return documents, nil
+}
+
+// walkSampleFields records the type and length of every leaf field, array elements share the "path[]" entry
+func walkSampleFields(path string, value interface{}, fields map[string]*fieldAccumulator, present map[string]bool) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for name, child := range typed {
+			childPath := name
+			if path != "" {
+				childPath = path + "." + name
+			}
+			walkSampleFields(childPath, child, fields, present)
+		}
+		return
+	case []interface{}:
+		for _, child := range typed {
+			walkSampleFields(path+"[]", child, fields, present)
+		}
+		return
+	}
+
+	var fieldType string
+	var length int64
+	switch typed := value.(type) {
+	case string:
+		fieldType, length = "string", int64(len(typed))
+	case json.Number:
+		fieldType, length = "number", int64(len(typed.String()))
+	case bool:
+		fieldType, length = "boolean", int64(len(fmt.Sprint(typed)))
+	default:
+		fieldType, length = "null", 4
+	}
+
+	field := fields[path]
+	if field == nil {
+		field = &fieldAccumulator{types: map[string]bool{}}
+		fields[path] = field
+	}
+	field.types[fieldType] = true
+	field.totalLength += length
+	field.maxLength = max(field.maxLength, length)
+	present[path] = true
+}
@@ -0,0 +1,290 @@
+package calculator
+
+import (
+	"sort"
+	"strings"
+	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
+)
+
+// hoursPerMonth is the average number of hours in a month
+const hoursPerMonth = 730
+
+// referencePricing holds indicative on-demand prices used when the request brings none
+var referencePricing = models.Pricing{
+	VCPUHour:    0.04,
+	RAMGBHour:   0.005,
+	DiskGBMonth: 0.08,
+	IOPSMonth:   0.005,
+}
+
+// Contention thresholds deciding whether services should be isolated
+const (
+	writeHeavyMutationsPerSec = 5000 // mutations per second above which index maintenance competes with Data writes
+	lowResidentRatio          = 30   // resident ratio (In %) below which background fetches keep the Data disks busy
+	largeSearchPercent        = 20   // % of the dataset indexed by Search above which it competes with Data for memory
+	maxGrowthIterations       = 20   // node additions tried while a group exceeds the per-node limits
+)
+
+// topologyCandidate is a service group layout before it is estimated
+type topologyCandidate struct {
+	name       string
+	groups     [][]string
+	tradeOffs  []string
+	contention []string // reasons the services sharing nodes with Data get in each other's way
+}
+
+// RecommendTopology proposes service group layouts for the dataset and workload, priced and ranked best first
+func RecommendTopology(request models.TopologyRequest) models.TopologyResponse {
+	pricing := referencePricing
+	if request.Pricing != nil {
+		pricing = *request.Pricing
+	}
+
+	// Step 1: Apply the preset dataset and workload of the workload nature. The layouts replace its service groups,
+	// the DCP consumers are seeded from the groups of every layout so the preset's services do not leak in.
+	base := request.ComputeRequest
+	applyWorkloadPreset(&base)
+	base.WorkloadNature = "override"
+	base.ServiceGroups = nil
+
+	// Step 2: Services the dataset and workload need, and the resident ratio the estimates size Data for
+	needed := neededServices(base)
+	effectiveResidentRatio, _ := residentRatio(base, dataServiceOverhead(base))
+
+	// Step 3: Candidate layouts, estimated with node counts growing until every group fits the per-node limits
+	var recommendations []models.TopologyRecommendation
+	for _, candidate := range topologyCandidates(base, needed, effectiveResidentRatio) {
+		groups := make([]models.ServiceGroup, 0, len(candidate.groups))
+		for _, groupServices := range candidate.groups {
+			groups = append(groups, models.ServiceGroup{
+				Name:      joinServices(groupServices),
+				Services:  groupServices,
+				NoOfNodes: initialNodes(base.Dataset, groupServices),
+				DiskType:  "gp3",
+			})
+		}
+
+		estimate := estimateTopology(base, groups)
+		recommendations = append(recommendations, models.TopologyRecommendation{
+			Name:          candidate.name,
+			ServiceGroups: groups,
+			Nodes:         estimate.Summary.NodesAllocated,
+			MonthlyCost:   monthlyCost(estimate, pricing),
+			TradeOffs:     candidate.tradeOffs,
+			Contention:    candidate.contention,
+			Estimate:      estimate,
+		})
+	}
+
+	// Step 4: Rank the layouts, blocked ones last, then layouts whose co-located services contend, then cheaper ones first
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Estimate.Blocked != recommendations[j].Estimate.Blocked {
+			return !recommendations[i].Estimate.Blocked
+		}
+		if contendedI, contendedJ := len(recommendations[i].Contention) > 0, len(recommendations[j].Contention) > 0; contendedI != contendedJ {
+			return !contendedI
+		}
+		return recommendations[i].MonthlyCost < recommendations[j].MonthlyCost
+	})
+	for i := range recommendations {
+		recommendations[i].Rank = i + 1
+	}
+
+	return models.TopologyResponse{Services: needed, Recommendations: recommendations}
+}
+
+// neededServices returns the services the dataset and workload of the request use
+func neededServices(request models.ComputeRequest) []string {
+	dataset, workload := request.Dataset, request.Workload
+	needed := []string{"data"}
+	if dataset.PercentIndexesOfDataset > 0 || workload.SQLQueriesPerSec > 0 || request.DCPConsumers.Indexes > 0 {
+		needed = append(needed, "index", "query")
+	}
+	if dataset.PercentFullTextSearchOfDataset > 0 || request.DCPConsumers.SearchIndexes > 0 {
+		needed = append(needed, "search")
+	}
+	if request.DCPConsumers.EventingFunctions > 0 {
+		needed = append(needed, "eventing")
+	}
+	if dataset.PercentOperationalAnalyticsOfDataset > 0 || len(dataset.AnalyticsDatasets) > 0 || workload.AnalyticsConcurrentQueries > 0 {
+		needed = append(needed, "analytics")
+	}
+	if request.SyncGateway.ConnectedDevices > 0 || request.SyncGateway.PercentImportProcessing > 0 {
+		needed = append(needed, "sync_gateway")
+	}
+	return needed
+}
+
+// topologyCandidates returns the layouts worth pricing, layouts the guardrails do not allow are left out.
+// residentRatio is the resident ratio Data is sized for, 0 when it is unknown.
+func topologyCandidates(request models.ComputeRequest, needed []string, residentRatio int64) []topologyCandidate {
+	has := map[string]bool{}
+	for _, service := range needed {
+		has[service] = true
+	}
+
+	// Step 1: Contention between the Data service and the services it could share nodes with
+	mutationsPerSec := request.Workload.WritesPerSec + request.Workload.DeletesPerSec
+	var contention []string
+	if has["index"] && mutationsPerSec > writeHeavyMutationsPerSec {
+		contention = append(contention, "index maintenance competes with Data writes for CPU and disk IO")
+	}
+	if residentRatio > 0 && residentRatio < lowResidentRatio {
+		contention = append(contention, "background fetches of the low resident ratio keep the Data disks busy")
+	}
+	if has["search"] && request.Dataset.PercentFullTextSearchOfDataset > largeSearchPercent {
+		contention = append(contention, "Search indexing competes with Data for memory")
+	}
+
+	// Step 2: Services always running on nodes of their own
+	var isolated [][]string
+	for _, service := range []string{"eventing", "analytics", "sync_gateway"} {
+		if has[service] {
+			isolated = append(isolated, []string{service})
+		}
+	}
+
+	// Step 3: Layouts of the Data, Index, Query and Search services
+	var indexQuery, withSearch []string
+	if has["index"] {
+		indexQuery = []string{"index", "query"}
+	}
+	withSearch = append(withSearch, indexQuery...)
+	if has["search"] {
+		withSearch = append(withSearch, "search")
+	}
+
+	colocated := topologyCandidate{
+		name:      "co-located",
+		groups:    [][]string{append([]string{"data"}, withSearch...)},
+		tradeOffs: append([]string{"fewest nodes, services share the cost of every node"}, contention...),
+	}
+	// contention only matters when Data shares its nodes, it ranks the co-located layout behind the separated ones
+	if len(withSearch) > 0 {
+		colocated.contention = contention
+	}
+	separated := topologyCandidate{
+		name:      "data separated",
+		groups:    [][]string{{"data"}},
+		tradeOffs: []string{"Data keeps its memory and disk IO to itself", "the other services scale independently of Data"},
+	}
+	if len(withSearch) > 0 {
+		separated.groups = append(separated.groups, withSearch)
+	}
+	isolatedAll := topologyCandidate{
+		name:      "fully isolated",
+		groups:    [][]string{{"data"}},
+		tradeOffs: []string{"every service scales and fails independently", "most nodes and the highest minimum cost"},
+	}
+	for _, service := range withSearch {
+		isolatedAll.groups = append(isolatedAll.groups, []string{service})
+	}
+	if len(contention) == 0 {
+		separated.tradeOffs = append(separated.tradeOffs, "no contention found that requires the separation")
+	}
+
+	// Step 4: Keep the distinct layouts the guardrails allow
+	var candidates []topologyCandidate
+	seen := map[string]bool{}
+	for _, candidate := range []topologyCandidate{colocated, separated, isolatedAll} {
+		candidate.groups = append(candidate.groups, isolated...)
+		key := layoutKey(candidate.groups)
+		if seen[key] || !allowedLayout(candidate.groups) {
+			continue
+		}
+		seen[key] = true
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// scalingWarning reports whether the warning is resolved by adding nodes to its group
+func scalingWarning(code string) bool {
+	switch code {
+	case "DISK_CAP_EXCEEDED", "DISK_IO_CAP_EXCEEDED", "INSTANCE_CAP_EXCEEDED", "UTILIZATION_ABOVE_TARGET":
+		return true
+	}
+	return false
+}
+
+// estimateTopology estimates the layout, adding nodes to the groups exceeding the per-node limits
+func estimateTopology(request models.ComputeRequest, groups []models.ServiceGroup) models.ComputeResponse {
+	request.ServiceGroups = groups
+	estimate := EstimateResources(request)
+	for iteration := 0; iteration < maxGrowthIterations; iteration++ {
+		// every group exceeding a limit gets a node per iteration
+		exceeding := map[string]bool{}
+		for _, warning := range estimate.Warnings {
+			if scalingWarning(warning.Code) {
+				exceeding[warning.Group] = true
+			}
+		}
+		grown := false
+		for i := range groups {
+			if exceeding[groupName(groups[i])] {
+				groups[i].NoOfNodes++
+				grown = true
+			}
+		}
+		if !grown {
+			break
+		}
+		request.ServiceGroups = groups
+		estimate = EstimateResources(request)
+	}
+	return estimate
+}
+
+// initialNodes returns the starting node count of a group, Data needs a node per copy and at least three
+func initialNodes(dataset models.Dataset, groupServices []string) int64 {
+	if hasService(groupServices, "data") {
+		return max(3, services.BucketReplicas(dataset)+1)
+	}
+	return 2
+}
+
+// monthlyCost prices the estimate of every group
+func monthlyCost(estimate models.ComputeResponse, pricing models.Pricing) float64 {
+	var cost float64
+	for _, result := range estimate.ServiceGroupsResults {
+		perNode := float64(result.EstimatedCPU)*pricing.VCPUHour*hoursPerMonth +
+			float64(result.EstimatedRAM)*pricing.RAMGBHour*hoursPerMonth +
+			float64(result.EstimatedDisk)*pricing.DiskGBMonth +
+			max(float64(result.EstimatedDiskIO)-minDiskIOPerNode, 0)*pricing.IOPSMonth
+		cost += perNode * float64(result.Nodes)
+	}
+	return services.Round(cost, 2)
+}
+
+// allowedLayout reports whether every group of the layout is a combination the guardrails allow
+func allowedLayout(groups [][]string) bool {
+	for _, rule := range guardrails.Rules {
+		if rule.Operator != "in" {
+			continue
+		}
+		for _, groupServices := range groups {
+			if _, failed := evaluateGuardrailRule(rule, guardrailScope{services: groupServices}); failed {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// layoutKey identifies a layout independent of the order of its groups
+func layoutKey(groups [][]string) string {
+	var keys []string
+	for _, groupServices := range groups {
+		sorted := append([]string(nil), groupServices...)
+		sort.Strings(sorted)
+		keys = append(keys, joinServices(sorted))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "|")
+}
+
+// joinServices names a group by its services
+func joinServices(groupServices []string) string {
+	return groupName(models.ServiceGroup{Services: groupServices})
+}
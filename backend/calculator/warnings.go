@@ -0,0 +1,276 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
+)
+
+// groupName identifies a service group in the warnings, by its name or else by its services
+func groupName(group models.ServiceGroup) string {
+	if group.Name != "" {
+		return group.Name
+	}
+	return strings.Join(group.Services, "+")
+}
+
+// groupWarnings returns the demands of a group the estimate had to clamp to the per-node limits, sizingNodes share the demand
+func groupWarnings(group models.ServiceGroup, sizingNodes int64, ramList, cpuList, diskList, diskIOList []float64) []models.Warning {
+	if sizingNodes <= 0 {
+		return []models.Warning{{
+			Code:        "NO_NODES",
+			Severity:    models.SeverityError,
+			Group:       groupName(group),
+			Message:     "service group has no nodes, its services are not sized",
+			Remediation: "set no_of_nodes for the group",
+		}}
+	}
+
+	var warnings []models.Warning
+	nodes := float64(sizingNodes)
+	tolerated := group.NoOfNodes - sizingNodes
+	name := groupName(group)
+
+	// Step 1: Disk space above the per-node cap
+	diskDemand := sumOf(diskList) / nodes
+	if diskDemand > maxDiskPerNode {
+		warnings = append(warnings, models.Warning{
+			Code:        "DISK_CAP_EXCEEDED",
+			Severity:    models.SeverityError,
+			Group:       name,
+			Service:     largestService(group.Services, diskList),
+			Message:     fmt.Sprintf("disk demand of %.1f TB per node exceeds the per-node cap of %.0f TB, the estimate is capped", diskDemand/1024, float64(maxDiskPerNode)/1024),
+			Remediation: fmt.Sprintf("add nodes to the group, at least %d are needed", ceilNodes(sumOf(diskList), maxDiskPerNode)+tolerated),
+		})
+	}
+
+	// Step 2: Disk IO above the maximum of the disk type
+	diskIODemand := sumOf(diskIOList) / nodes
+	switch {
+	case group.DiskType == "gp3" && diskIODemand > maxDiskIOGP3:
+		warnings = append(warnings, models.Warning{
+			Code:        "DISK_IO_CAP_EXCEEDED",
+			Severity:    models.SeverityWarning,
+			Group:       name,
+			Service:     largestService(group.Services, diskIOList),
+			Message:     fmt.Sprintf("IOPS demand of %.0f per node exceeds the gp3 maximum of %d, the estimate is capped", diskIODemand, maxDiskIOGP3),
+			Remediation: fmt.Sprintf("consider io2 disks or at least %d nodes", ceilNodes(sumOf(diskIOList), maxDiskIOGP3)+tolerated),
+		})
+	case group.DiskType == "io2" && diskIODemand > maxDiskIOIO2:
+		warnings = append(warnings, models.Warning{
+			Code:        "DISK_IO_CAP_EXCEEDED",
+			Severity:    models.SeverityError,
+			Group:       name,
+			Service:     largestService(group.Services, diskIOList),
+			Message:     fmt.Sprintf("IOPS demand of %.0f per node exceeds the io2 maximum of %d, the estimate is capped", diskIODemand, maxDiskIOIO2),
+			Remediation: fmt.Sprintf("add nodes to the group, at least %d are needed", ceilNodes(sumOf(diskIOList), maxDiskIOIO2)+tolerated),
+		})
+	}
+
+	// Step 3: RAM and CPU above the largest instance
+	largest := models.Instances[len(models.Instances)-1]
+	ramDemand := CalculateRAM(ramList, group.Services, sizingNodes, group.MemoryAllocation, defaultTargetRAM)
+	cpuDemand := CalculateCPU(cpuList, sizingNodes, 100)
+	if ramDemand > float64(largest.RAM) || cpuDemand > float64(largest.VCPU) {
+		warnings = append(warnings, models.Warning{
+			Code:        "INSTANCE_CAP_EXCEEDED",
+			Severity:    models.SeverityError,
+			Group:       name,
+			Message:     fmt.Sprintf("demand of %.0f GB RAM and %.0f vCPU per node exceeds the largest instance (%d GB, %d vCPU), the estimate is capped", ramDemand, cpuDemand, largest.RAM, largest.VCPU),
+			Remediation: "add nodes to the group or move services to a group of their own",
+		})
+	}
+	return warnings
+}
+
+// allocationWarnings returns a warning when the memory allocation of a group is unknown, its quotas are then split equally
+func allocationWarnings(group models.ServiceGroup) []models.Warning {
+	switch group.MemoryAllocation {
+	case "", AllocationEqual, AllocationDemand:
+		return nil
+	}
+	return []models.Warning{{
+		Code:        "MEMORY_ALLOCATION_UNKNOWN",
+		Severity:    models.SeverityWarning,
+		Group:       groupName(group),
+		Message:     fmt.Sprintf("unknown memory allocation %q, the memory is split equally among the services", group.MemoryAllocation),
+		Remediation: fmt.Sprintf("use %s or %s", AllocationEqual, AllocationDemand),
+	}}
+}
+
+// targetUtilizationWarnings returns a warning when the chosen nodes of a group run above the target utilisation,
+// the utilisation is taken after the tolerated failure the group is sized for
+func targetUtilizationWarnings(group models.ServiceGroup, utilization, target models.Utilization) []models.Warning {
+	var above []string
+	for _, resource := range []struct {
+		name                string
+		utilization, target float64
+	}{
+		{"RAM", utilization.RAM, target.RAM},
+		{"CPU", utilization.CPU, target.CPU},
+		{"disk", utilization.Disk, target.Disk},
+		{"disk IO", utilization.DiskIO, target.DiskIO},
+	} {
+		if resource.utilization > resource.target {
+			above = append(above, fmt.Sprintf("%s at %.1f%% (target %.0f%%)", resource.name, resource.utilization, resource.target))
+		}
+	}
+	if len(above) == 0 {
+		return nil
+	}
+	return []models.Warning{{
+		Code:        "UTILIZATION_ABOVE_TARGET",
+		Severity:    models.SeverityWarning,
+		Group:       groupName(group),
+		Message:     fmt.Sprintf("the chosen nodes run above the target utilisation: %s", strings.Join(above, ", ")),
+		Remediation: "add nodes to the group or move services to a group of their own",
+	}}
+}
+
+// durabilityWarnings returns the reasons the bucket's durability level cannot be satisfied, dataNodes counts the Data service nodes
+func durabilityWarnings(dataset models.Dataset, dataNodes int64, dataGroup string) []models.Warning {
+	level, ok := services.DurabilityLevel(dataset)
+	if !ok {
+		return []models.Warning{{
+			Code:        "DURABILITY_UNKNOWN_LEVEL",
+			Severity:    models.SeverityWarning,
+			Group:       dataGroup,
+			Service:     "data",
+			Message:     fmt.Sprintf("unknown durability level %q, sized without synchronous writes", dataset.Bucket.DurabilityLevel),
+			Remediation: "use none, majority, majorityAndPersistActive or persistToMajority",
+		}}
+	}
+	if level == services.DurabilityNone {
+		return nil
+	}
+
+	var warnings []models.Warning
+	replicas := services.BucketReplicas(dataset)
+	if replicas > services.MaxDurableReplicas {
+		warnings = append(warnings, models.Warning{
+			Code:        "DURABILITY_REPLICAS",
+			Severity:    models.SeverityError,
+			Group:       dataGroup,
+			Service:     "data",
+			Message:     fmt.Sprintf("durability level %s cannot be satisfied with %d replicas, synchronous writes support up to %d", level, replicas, services.MaxDurableReplicas),
+			Remediation: fmt.Sprintf("lower the replicas to %d", services.MaxDurableReplicas),
+		})
+	}
+	if majority := services.MajorityCopies(replicas); dataNodes > 0 && min(dataNodes, replicas+1) < majority {
+		warnings = append(warnings, models.Warning{
+			Code:        "DURABILITY_NODES",
+			Severity:    models.SeverityError,
+			Group:       dataGroup,
+			Service:     "data",
+			Message:     fmt.Sprintf("durability level %s needs %d Data nodes to reach a majority of %d copies, %d configured", level, majority, replicas+1, dataNodes),
+			Remediation: fmt.Sprintf("run the Data service on at least %d nodes", majority),
+		})
+	}
+	return warnings
+}
+
+// compactionWarnings returns a warning when the compactors of a Data node cannot keep the fragmentation at its threshold
+func compactionWarnings(dataset models.Dataset, workload models.Workload, dataNodes int64, dataGroup string) []models.Warning {
+	if dataNodes == 0 {
+		return nil
+	}
+	required := services.CompactionThroughput(dataset, workload) / float64(dataNodes)
+	capacity := services.CompactionCapacity(dataset)
+	if required <= capacity {
+		return nil
+	}
+	return []models.Warning{{
+		Code:        "COMPACTION_THROUGHPUT",
+		Severity:    models.SeverityWarning,
+		Group:       dataGroup,
+		Service:     "data",
+		Message:     fmt.Sprintf("compaction needs %.1f MB/s per Data node but %d parallel compactors reach %.0f MB/s", required, max(dataset.Bucket.Compaction.Parallelism, 1), capacity),
+		Remediation: "raise the compaction parallelism or widen the compaction window",
+	}}
+}
+
+// purgeIntervalWarnings returns a warning for every component that replays deletes and needs a longer metadata purge interval
+func purgeIntervalWarnings(request models.ComputeRequest, dataGroup string) []models.Warning {
+	var warnings []models.Warning
+	purgeInterval := services.PurgeIntervalDays(request.Dataset)
+
+	if request.DCPConsumers.XDCRReplications > 0 && purgeInterval < services.MinPurgeIntervalDaysXDCR {
+		warnings = append(warnings, models.Warning{
+			Code:        "PURGE_INTERVAL_XDCR",
+			Severity:    models.SeverityWarning,
+			Group:       dataGroup,
+			Service:     "data",
+			Message:     fmt.Sprintf("purge interval of %g days is shorter than the %g days XDCR needs to replicate deletes after a paused replication", purgeInterval, services.MinPurgeIntervalDaysXDCR),
+			Remediation: fmt.Sprintf("raise the purge interval to %g days", services.MinPurgeIntervalDaysXDCR),
+		})
+	}
+	if request.SyncGateway.ConnectedDevices > 0 && purgeInterval < services.MinPurgeIntervalDaysSyncGateway {
+		warnings = append(warnings, models.Warning{
+			Code:        "PURGE_INTERVAL_SYNC_GATEWAY",
+			Severity:    models.SeverityWarning,
+			Group:       dataGroup,
+			Service:     "data",
+			Message:     fmt.Sprintf("purge interval of %g days is shorter than the %g days Sync Gateway needs to sync deletes to clients that were offline", purgeInterval, services.MinPurgeIntervalDaysSyncGateway),
+			Remediation: fmt.Sprintf("raise the purge interval to %g days", services.MinPurgeIntervalDaysSyncGateway),
+		})
+	}
+	return warnings
+}
+
+// analyticsSourceWarnings returns a warning for every Analytics dataset whose source collection is not one of the
+// collections of the dataset, it is then sized on every document of the bucket
+func analyticsSourceWarnings(dataset models.Dataset, groups []models.ServiceGroup) []models.Warning {
+	var analyticsGroup string
+	for _, group := range groups {
+		if hasService(group.Services, "analytics") {
+			analyticsGroup = groupName(group)
+		}
+	}
+	if analyticsGroup == "" {
+		return nil
+	}
+
+	var warnings []models.Warning
+	for _, analyticsDataset := range dataset.AnalyticsDatasets {
+		if analyticsDataset.SourceCollection == "" || services.HasCollection(dataset, analyticsDataset.SourceCollection) {
+			continue
+		}
+		warnings = append(warnings, models.Warning{
+			Code:        "ANALYTICS_SOURCE_COLLECTION_UNKNOWN",
+			Severity:    models.SeverityWarning,
+			Group:       analyticsGroup,
+			Service:     "analytics",
+			Message:     fmt.Sprintf("source collection %q of Analytics dataset %q is not a collection of the dataset, it is sized on every document of the bucket", analyticsDataset.SourceCollection, analyticsDataset.Name),
+			Remediation: "add the collection and its document count to the dataset's collections",
+		})
+	}
+	return warnings
+}
+
+// sumOf returns the sum of the values
+func sumOf(values []float64) float64 {
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// largestService returns the service with the highest value
+func largestService(services []string, values []float64) string {
+	var largest string
+	var largestValue float64
+	for i, value := range values {
+		if i < len(services) && value > largestValue {
+			largest, largestValue = services[i], value
+		}
+	}
+	return largest
+}
+
+// ceilNodes returns the units of the given capacity the total needs
+func ceilNodes(total, capacity float64) int64 {
+	return int64(math.Ceil(total / capacity))
+}
@@ -0,0 +1,65 @@
+package calculator
+
+import (
+	"fmt"
+	"workload-estimator-poc/models"
+	"workload-estimator-poc/services"
+)
+
+// zoneWarnings returns the placement problems of a group spread over availability zones, dataCopies is non-zero for Data groups
+func zoneWarnings(group models.ServiceGroup, dataCopies int64) []models.Warning {
+	zones := availabilityZones(group)
+	if zones <= 1 || group.NoOfNodes <= 0 {
+		return nil
+	}
+
+	var warnings []models.Warning
+	name := groupName(group)
+
+	// Step 1: Every zone needs the same number of nodes
+	if group.NoOfNodes < zones {
+		warnings = append(warnings, models.Warning{
+			Code:        "ZONES_WITHOUT_NODES",
+			Severity:    models.SeverityWarning,
+			Group:       name,
+			Message:     fmt.Sprintf("%d nodes leave %d of %d availability zones empty", group.NoOfNodes, zones-group.NoOfNodes, zones),
+			Remediation: fmt.Sprintf("run the group on %d nodes", zones),
+		})
+	} else if group.NoOfNodes%zones != 0 {
+		warnings = append(warnings, models.Warning{
+			Code:        "ZONES_UNEVEN",
+			Severity:    models.SeverityWarning,
+			Group:       name,
+			Message:     fmt.Sprintf("%d nodes do not divide evenly over %d availability zones, the largest zone holds %d nodes", group.NoOfNodes, zones, (group.NoOfNodes+zones-1)/zones),
+			Remediation: fmt.Sprintf("run the group on %d or %d nodes", group.NoOfNodes/zones*zones, (group.NoOfNodes/zones+1)*zones),
+		})
+	}
+
+	// Step 2: Every copy of a vBucket must be placed in a zone of its own
+	if dataCopies > 0 && min(zones, group.NoOfNodes) < dataCopies {
+		warnings = append(warnings, models.Warning{
+			Code:        "ZONES_REPLICA_PLACEMENT",
+			Severity:    models.SeverityWarning,
+			Group:       name,
+			Service:     "data",
+			Message:     fmt.Sprintf("%d copies of the data cannot be placed in different zones of %d availability zones", dataCopies, min(zones, group.NoOfNodes)),
+			Remediation: fmt.Sprintf("spread the Data nodes over %d availability zones or lower the replicas", dataCopies),
+		})
+	}
+	return warnings
+}
+
+// interZoneTraffic estimates the traffic between the availability zones of the Data group
+func interZoneTraffic(request models.ComputeRequest, overhead services.DataOverhead, zones int64) *models.InterZoneTraffic {
+	replication, dcp := services.InterZoneTraffic(request.Dataset, request.Workload, overhead, zones)
+	if replication+dcp == 0 {
+		return nil
+	}
+	const secondsPerMonth = hoursPerMonth * 3600
+	return &models.InterZoneTraffic{
+		AvailabilityZones: zones,
+		Replication:       services.Round(replication/1024/1024, 2),
+		DCP:               services.Round(dcp/1024/1024, 2),
+		MonthlyTransfer:   services.Round((replication+dcp)*secondsPerMonth/1024/1024/1024, 1),
+	}
+}
@@ -5,3 +5,5 @@ go 1.23.0
 require github.com/gorilla/mux v1.8.1
 
 require github.com/rs/cors v1.11.1
+
+require github.com/golang/snappy v0.0.4
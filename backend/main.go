@@ -4,6 +4,7 @@ import (
 	"workload-estimator-poc/calculator"
 	"workload-estimator-poc/models"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,6 +13,19 @@ import (
 	"github.com/rs/cors"
 )
 
+// maxSampleBytes is the largest document sample /analyze-sample accepts (In bytes)
+const maxSampleBytes = 64 * 1024 * 1024
+
+// logRequest logs the decoded request of an estimate handler
+func logRequest(request any) {
+	requestJSON, err := json.MarshalIndent(request, "", "  ")
+	if err == nil {
+		log.Printf("Received Request: \n%s\n", string(requestJSON))
+	} else {
+		log.Println("Failed to log request")
+	}
+}
+
 func estimateHandler(w http.ResponseWriter, r *http.Request) {
 	var request models.ComputeRequest
 
@@ -21,10 +35,85 @@ func estimateHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
+	logRequest(request)
 
 	// Call the calculator
 	response := calculator.EstimateResources(request)
 
+	// Send response, an estimate failing a blocking guardrail is flagged as blocked and carries the failing guardrails in its warnings
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func recommendTopologyHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.TopologyRequest
+
+	// Decode JSON request
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	logRequest(request)
+
+	// Price and rank the candidate layouts
+	response := calculator.RecommendTopology(request)
+
+	// Send response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func projectHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.ProjectionRequest
+
+	// Decode JSON request
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	logRequest(request)
+
+	// Estimate every month of the growth
+	response := calculator.ProjectGrowth(request)
+
+	// Send response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func guardrailsHandler(w http.ResponseWriter, r *http.Request) {
+	// Send the guardrail rule set
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calculator.Guardrails())
+}
+
+func analyzeSampleHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.SampleAnalysisRequest
+
+	// Decode JSON request, the sample is bounded
+	r.Body = http.MaxBytesReader(w, r.Body, maxSampleBytes)
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Sample larger than %d MB", maxSampleBytes/1024/1024), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	// Analyze the sample documents, the sample may hold customer data so only its size is logged
+	response, err := calculator.AnalyzeSample(request)
+	if err != nil {
+		log.Printf("Received sample of %d bytes: %v\n", len(request.Documents), err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Received sample of %d documents, %d bytes\n", response.SampleSize, len(request.Documents))
+
 	// Send response
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -34,6 +123,10 @@ func main() {
 	router := mux.NewRouter()
 
 	router.HandleFunc("/estimate", estimateHandler).Methods("POST")
+	router.HandleFunc("/analyze-sample", analyzeSampleHandler).Methods("POST")
+	router.HandleFunc("/guardrails", guardrailsHandler).Methods("GET")
+	router.HandleFunc("/recommend-topology", recommendTopologyHandler).Methods("POST")
+	router.HandleFunc("/project", projectHandler).Methods("POST")
 
 	// CORS configuration
 	corsHandler := cors.New(cors.Options{
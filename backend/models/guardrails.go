@@ -0,0 +1,23 @@
+package models
+
+// GuardrailRuleSet is a versioned set of Capella guardrails
+type GuardrailRuleSet struct {
+	Version 		string          		`json:"version"`
+	Rules   		[]GuardrailRule 		`json:"rules"`
+}
+
+// GuardrailRule checks a metric of the cluster or of every service group against a threshold
+type GuardrailRule struct {
+	ID                 		string            		`json:"id"`
+	Description        		string            		`json:"description"`
+	Scope              		string            		`json:"scope"`																// "cluster" or "group"
+	Service            		string            		`json:"service,omitempty"`										// only groups, or clusters, running the service
+	When               		map[string]string 		`json:"when,omitempty"`												// conditions on the request, e.g. the storage engine
+	Metric             		string            		`json:"metric,omitempty"`
+	Operator           		string            		`json:"operator"`															// "<", "<=", ">", ">=" or "in" for allowed_service_sets
+	Threshold          		float64           		`json:"threshold,omitempty"`
+	ThresholdMetric    		string            		`json:"threshold_metric,omitempty"`					// compares against another metric instead of threshold
+	AllowedServiceSets 		[][]string        		`json:"allowed_service_sets,omitempty"`
+	Action             		string            		`json:"action"`																// "block" or "warn"
+	Remediation        		string            		`json:"remediation,omitempty"`
+}
@@ -0,0 +1,52 @@
+package models
+
+// ProjectionRequest is the input of the growth projection, the estimate request describes month 0
+type ProjectionRequest struct {
+	ComputeRequest
+	Growth 			Growth 			`json:"growth"`
+}
+
+// Growth describes how the dataset and the workload grow every month
+type Growth struct {
+	Months                		int64   		`json:"months"`									// months projected, 12 when unset
+	DocumentGrowthPercent 		float64 		`json:"document_growth_percent"`		// % more documents every month, compounded
+	DocumentsPerMonth     		int64   		`json:"documents_per_month"`				// documents added every month on top of the compounded growth
+	OpsGrowthPercent      		float64 		`json:"ops_growth_percent"`					// % more operations every month, compounded, applies to every workload rate
+	MaxDocuments          		int64   		`json:"max_documents"`							// retention cap, older documents expire once the bucket holds this many
+	MaxOpsGrowthPercent   		float64 		`json:"max_ops_growth_percent"`			// cap on the total growth of the workload rates, unset is unbounded
+}
+
+// ProjectedGroup holds the nodes a service group needs in a month of the projection
+type ProjectedGroup struct {
+	Name        				string      		`json:"name"`
+	Nodes       				int64       		`json:"nodes"`
+	PerNode     				Resources   		`json:"per_node"`
+	Total       				Resources   		`json:"total"`
+	Utilization 				Utilization 		`json:"utilization"`
+}
+
+// ProjectionMonth is a month of the projection
+type ProjectionMonth struct {
+	Month        				int64            		`json:"month"`
+	Documents    				int64            		`json:"documents"`
+	OpsFactor    				float64          		`json:"ops_factor"`								// workload rates relative to month 0
+	Groups       				[]ProjectedGroup 		`json:"groups"`
+	Total        				Resources        		`json:"total"`
+	Blocked      				bool             		`json:"blocked"`
+}
+
+// ScaleUp is a month in which a service group outgrows its nodes
+type ScaleUp struct {
+	Month     				int64    		`json:"month"`
+	Group     				string   		`json:"group"`
+	FromNodes 				int64    		`json:"from_nodes"`
+	ToNodes   				int64    		`json:"to_nodes"`
+	Reasons   				[]string 		`json:"reasons"`											// the per-node limits the group exceeds
+	Unresolved 				bool     		`json:"unresolved,omitempty"`								// the group still exceeds its limits on to_nodes, the reasons are the remaining ones
+}
+
+// ProjectionResponse holds the timeline of the projection and the scale-ups it calls for
+type ProjectionResponse struct {
+	Timeline 				[]ProjectionMonth 		`json:"timeline"`
+	ScaleUps 				[]ScaleUp         		`json:"scale_ups"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "encoding/json"
+
+// SampleAnalysisRequest holds a sample of real documents and their keys
+type SampleAnalysisRequest struct {
+	Documents     json.RawMessage `json:"documents"`       // JSON array of documents, or a string holding NDJSON
+	Keys          []string        `json:"keys"`            // document keys, in the order of the documents
+	NoOfDocuments int64           `json:"no_of_documents"` // documents in the full dataset, defaults to the sample size
+	SearchFields  []string        `json:"search_fields"`   // field paths indexed by Search, defaults to every string field
+}
+
+// FieldStatistics holds the statistics of a single field across the sample
+type FieldStatistics struct {
+	Path           string   `json:"path"`
+	Types          []string `json:"types"`
+	PresentPercent float64  `json:"present_percent"`
+	AverageLength  float64  `json:"average_length"` // in bytes, over the documents holding the field
+	MaxLength      int64    `json:"max_length"`     // in bytes
+}
+
+// SampleAnalysisResponse holds the dataset derived from a document sample and the per-field statistics
+type SampleAnalysisResponse struct {
+	Dataset             Dataset           `json:"dataset"`
+	SampleSize          int64             `json:"sample_size"`
+	AverageDocumentSize float64           `json:"average_document_size"` // in bytes
+	AverageKeySize      float64           `json:"average_key_size"`      // in bytes
+	CompressionRatio    float64           `json:"compression_ratio"`
+	Fields              []FieldStatistics `json:"fields"`
+}
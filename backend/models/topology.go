@@ -0,0 +1,33 @@
+package models
+
+// TopologyRequest is the input of the topology recommender, its service groups are ignored
+type TopologyRequest struct {
+	ComputeRequest
+	Pricing 		*Pricing 		`json:"pricing,omitempty"`						// unset uses the reference prices
+}
+
+// Pricing holds the on-demand price of every resource (In USD)
+type Pricing struct {
+	VCPUHour   		float64 		`json:"vcpu_hour"`
+	RAMGBHour  		float64 		`json:"ram_gb_hour"`
+	DiskGBMonth 	float64 		`json:"disk_gb_month"`
+	IOPSMonth  		float64 		`json:"iops_month"`									// per provisioned IOPS above the included baseline
+}
+
+// TopologyRecommendation is a candidate service group layout and its estimate
+type TopologyRecommendation struct {
+	Rank          		int             		`json:"rank"`
+	Name          		string          		`json:"name"`
+	ServiceGroups 		[]ServiceGroup  		`json:"service_groups"`
+	Nodes         		int64           		`json:"nodes"`
+	MonthlyCost   		float64         		`json:"monthly_cost"`						// USD
+	TradeOffs     		[]string        		`json:"trade_offs"`
+	Contention    		[]string        		`json:"contention,omitempty"`			// reasons co-located services contend, ranks the layout behind uncontended ones
+	Estimate      		ComputeResponse 		`json:"estimate"`
+}
+
+// TopologyResponse holds the candidate layouts, best first
+type TopologyResponse struct {
+	Services        		[]string                 		`json:"services"`				// services the dataset and workload need
+	Recommendations 		[]TopologyRecommendation 		`json:"recommendations"`
+}
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes. It is decoded from a plain number of bytes or from a unit-qualified
+// string such as "2.5KB" or "1.2GiB". KB, MB, GB and TB follow the same 1024 based convention as KiB, MiB, GiB and TiB.
+// Document and attachment sizes take their plain numbers in DocumentSizeUnit instead.
+type ByteSize float64
+
+// DocumentSizeUnit is the unit of plain document and attachment size numbers, the dataset's size_unit overrides it for the documents
+const DocumentSizeUnit = "KB"
+
+// byteUnits maps the accepted unit names (lower case) to their size in bytes
+var byteUnits = map[string]float64{
+	"":      1,
+	"b":     1,
+	"byte":  1,
+	"bytes": 1,
+	"k":     1024,
+	"kb":    1024,
+	"kib":   1024,
+	"m":     1024 * 1024,
+	"mb":    1024 * 1024,
+	"mib":   1024 * 1024,
+	"g":     1024 * 1024 * 1024,
+	"gb":    1024 * 1024 * 1024,
+	"gib":   1024 * 1024 * 1024,
+	"t":     1024 * 1024 * 1024 * 1024,
+	"tb":    1024 * 1024 * 1024 * 1024,
+	"tib":   1024 * 1024 * 1024 * 1024,
+}
+
+var byteSizePattern = regexp.MustCompile(`^\s*([0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?)\s*([A-Za-z]*)\s*$`)
+
+// ByteUnit returns the size in bytes of a unit name, an empty unit means bytes
+func ByteUnit(unit string) (float64, error) {
+	factor, ok := byteUnits[strings.ToLower(strings.TrimSpace(unit))]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", unit)
+	}
+	return factor, nil
+}
+
+// ParseByteSize parses a unit-qualified size, a value without unit is taken in defaultUnit
+func ParseByteSize(value string, defaultUnit string) (ByteSize, error) {
+	match := byteSizePattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %v", value, err)
+	}
+	unit := match[2]
+	if unit == "" {
+		unit = defaultUnit
+	}
+	factor, err := ByteUnit(unit)
+	if err != nil {
+		return 0, err
+	}
+	return ByteSize(number * factor), nil
+}
+
+// UnmarshalJSON accepts a plain number of bytes or a unit-qualified string
+func (s *ByteSize) UnmarshalJSON(data []byte) error {
+	var value string
+	if isJSONNumber(data) {
+		value = string(bytes.TrimSpace(data))
+	} else if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("size must be a number of bytes or a string such as \"2.5KB\": %s", data)
+	}
+	size, err := ParseByteSize(value, "B")
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+// rescaleDocumentSize converts a size decoded from a plain number of bytes to the document size unit
+func rescaleDocumentSize(raw json.RawMessage, size *ByteSize, factor float64) {
+	if isJSONNumber(raw) {
+		*size *= ByteSize(factor)
+	}
+}
+
+// isJSONNumber reports whether the raw JSON value is a plain number
+func isJSONNumber(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && (data[0] == '-' || (data[0] >= '0' && data[0] <= '9'))
+}
+
+// UnmarshalJSON decodes the dataset. Plain numbers given for the document sizes (average, histogram, percentiles and collections)
+// are taken in SizeUnit, DocumentSizeUnit when unset, unit-qualified strings carry their own unit. Key sizes and field lengths
+// are plain numbers of bytes. document_size_unit is accepted for size_unit.
+func (d *Dataset) UnmarshalJSON(data []byte) error {
+	type datasetFields Dataset
+	if err := json.Unmarshal(data, (*datasetFields)(d)); err != nil {
+		return err
+	}
+
+	var sizes struct {
+		DocumentSizeUnit      string          `json:"document_size_unit"`
+		AverageDocumentSize   json.RawMessage `json:"average_document_size"`
+		DocumentSizeHistogram []struct {
+			Size json.RawMessage `json:"size"`
+		} `json:"document_size_histogram"`
+		DocumentSizePercentiles []struct {
+			Size json.RawMessage `json:"size"`
+		} `json:"document_size_percentiles"`
+		Collections []struct {
+			AverageDocumentSize json.RawMessage `json:"average_document_size"`
+		} `json:"collections"`
+	}
+	if err := json.Unmarshal(data, &sizes); err != nil {
+		return err
+	}
+
+	if d.SizeUnit == "" {
+		d.SizeUnit = sizes.DocumentSizeUnit
+	}
+	unit := d.SizeUnit
+	if unit == "" {
+		unit = DocumentSizeUnit
+	}
+	factor, err := ByteUnit(unit)
+	if err != nil {
+		return err
+	}
+
+	rescaleDocumentSize(sizes.AverageDocumentSize, &d.AverageDocumentSize, factor)
+	for i, bucket := range sizes.DocumentSizeHistogram {
+		rescaleDocumentSize(bucket.Size, &d.DocumentSizeHistogram[i].Size, factor)
+	}
+	for i, percentile := range sizes.DocumentSizePercentiles {
+		rescaleDocumentSize(percentile.Size, &d.DocumentSizePercentiles[i].Size, factor)
+	}
+	for i, collection := range sizes.Collections {
+		rescaleDocumentSize(collection.AverageDocumentSize, &d.Collections[i].AverageDocumentSize, factor)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the dataset with every size in bytes, so the output decodes back to the same dataset
+func (d Dataset) MarshalJSON() ([]byte, error) {
+	type datasetFields Dataset
+	fields := datasetFields(d)
+	fields.SizeUnit = "B"
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes the Analytics index, key_bytes is accepted for key_size
+func (i *AnalyticsIndex) UnmarshalJSON(data []byte) error {
+	type indexFields AnalyticsIndex
+	var index struct {
+		indexFields
+		KeyBytes *ByteSize `json:"key_bytes"`
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return err
+	}
+	*i = AnalyticsIndex(index.indexFields)
+	if i.KeySize == 0 && index.KeyBytes != nil {
+		i.KeySize = *index.KeyBytes
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the Sync Gateway workload, a plain attachment size number is taken in DocumentSizeUnit
+func (w *SyncGatewayWorkload) UnmarshalJSON(data []byte) error {
+	type workloadFields SyncGatewayWorkload
+	if err := json.Unmarshal(data, (*workloadFields)(w)); err != nil {
+		return err
+	}
+	var sizes struct {
+		AverageAttachmentSize json.RawMessage `json:"average_attachment_size"`
+	}
+	if err := json.Unmarshal(data, &sizes); err != nil {
+		return err
+	}
+	factor, _ := ByteUnit(DocumentSizeUnit)
+	rescaleDocumentSize(sizes.AverageAttachmentSize, &w.AverageAttachmentSize, factor)
+	return nil
+}
+
+// MarshalJSON encodes the Sync Gateway workload with the attachment size in bytes, so the output decodes back to the same workload
+func (w SyncGatewayWorkload) MarshalJSON() ([]byte, error) {
+	type workloadFields SyncGatewayWorkload
+	return json.Marshal(struct {
+		workloadFields
+		AverageAttachmentSize string `json:"average_attachment_size"`
+	}{workloadFields(w), fmt.Sprintf("%gB", float64(w.AverageAttachmentSize))})
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value       string
+		defaultUnit string
+		want        ByteSize
+		wantErr     bool
+	}{
+		{value: "40", defaultUnit: "B", want: 40},
+		{value: "2", defaultUnit: "KB", want: 2048},
+		{value: "40B", defaultUnit: "KB", want: 40},
+		{value: "2.5KB", defaultUnit: "B", want: 2560},
+		{value: "2.5 kib", defaultUnit: "B", want: 2560},
+		{value: "1.5MB", defaultUnit: "B", want: 1.5 * 1024 * 1024},
+		{value: "1GiB", defaultUnit: "B", want: 1024 * 1024 * 1024},
+		{value: "1e3", defaultUnit: "B", want: 1000},
+		{value: ".5K", defaultUnit: "B", want: 512},
+		{value: "", defaultUnit: "B", wantErr: true},
+		{value: "abc", defaultUnit: "B", wantErr: true},
+		{value: "-1KB", defaultUnit: "B", wantErr: true},
+		{value: "2XB", defaultUnit: "B", wantErr: true},
+		{value: "2", defaultUnit: "XB", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := ParseByteSize(test.value, test.defaultUnit)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseByteSize(%q, %q) = %v, want an error", test.value, test.defaultUnit, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseByteSize(%q, %q) = %v, %v, want %v", test.value, test.defaultUnit, got, err, test.want)
+		}
+	}
+}
+
+func TestRequestSizeUnits(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        string
+		wantDocument   ByteSize
+		wantKey        ByteSize
+		wantField      ByteSize
+		wantIndexKey   ByteSize
+		wantAttachment ByteSize
+		wantHistogram  ByteSize
+	}{
+		{
+			name:         "plain document sizes in KB, keys and fields in bytes",
+			request:      `{"dataset":{"average_document_size":2,"average_key_size":40,"average_field_length":100}}`,
+			wantDocument: 2048, wantKey: 40, wantField: 100,
+		},
+		{
+			name:         "unit-qualified strings carry their own unit",
+			request:      `{"dataset":{"average_document_size":"512B","average_key_size":"0.5KB"}}`,
+			wantDocument: 512, wantKey: 512,
+		},
+		{
+			name:         "size_unit applies to the document sizes only",
+			request:      `{"dataset":{"size_unit":"B","average_document_size":300,"average_key_size":40,"document_size_histogram":[{"size":700,"percent":100}]}}`,
+			wantDocument: 300, wantKey: 40, wantHistogram: 700,
+		},
+		{
+			name:         "document_size_unit is accepted for size_unit",
+			request:      `{"dataset":{"document_size_unit":"MB","average_document_size":1}}`,
+			wantDocument: 1024 * 1024,
+		},
+		{
+			name:         "key_bytes is accepted for key_size",
+			request:      `{"dataset":{"analytics_datasets":[{"secondary_indexes":[{"key_bytes":16}]}]}}`,
+			wantIndexKey: 16,
+		},
+		{
+			name:         "key_size wins over key_bytes",
+			request:      `{"dataset":{"analytics_datasets":[{"secondary_indexes":[{"key_size":"1KB","key_bytes":16}]}]}}`,
+			wantIndexKey: 1024,
+		},
+		{
+			name:           "plain attachment sizes in KB",
+			request:        `{"sync_gateway":{"average_attachment_size":50}}`,
+			wantAttachment: 50 * 1024,
+		},
+	}
+	for _, test := range tests {
+		var request ComputeRequest
+		if err := json.Unmarshal([]byte(test.request), &request); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		dataset := request.Dataset
+		var indexKey, histogram ByteSize
+		if len(dataset.AnalyticsDatasets) > 0 && len(dataset.AnalyticsDatasets[0].SecondaryIndexes) > 0 {
+			indexKey = dataset.AnalyticsDatasets[0].SecondaryIndexes[0].KeySize
+		}
+		if len(dataset.DocumentSizeHistogram) > 0 {
+			histogram = dataset.DocumentSizeHistogram[0].Size
+		}
+		got := []ByteSize{dataset.AverageDocumentSize, dataset.AverageKeySize, dataset.AverageFieldLength, indexKey, request.SyncGateway.AverageAttachmentSize, histogram}
+		want := []ByteSize{test.wantDocument, test.wantKey, test.wantField, test.wantIndexKey, test.wantAttachment, test.wantHistogram}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: sizes = %v, want %v", test.name, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestRequestSizeUnitsRoundTrip(t *testing.T) {
+	var request ComputeRequest
+	input := `{"dataset":{"size_unit":"KB","average_document_size":2,"average_key_size":40},"sync_gateway":{"average_attachment_size":50}}`
+	if err := json.Unmarshal([]byte(input), &request); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ComputeRequest
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Dataset.AverageDocumentSize != request.Dataset.AverageDocumentSize ||
+		decoded.Dataset.AverageKeySize != request.Dataset.AverageKeySize ||
+		decoded.SyncGateway.AverageAttachmentSize != request.SyncGateway.AverageAttachmentSize {
+		t.Errorf("round trip of %s changed the sizes: %s", input, encoded)
+	}
+}
+
+func TestWorkloadProfileValidation(t *testing.T) {
+	factors := func(n int) []float64 {
+		values := make([]float64, n)
+		for i := range values {
+			values[i] = 1
+		}
+		return values
+	}
+	encode := func(profile map[string]any) string {
+		data, _ := json.Marshal(profile)
+		return string(data)
+	}
+	tests := []struct {
+		name    string
+		profile string
+		wantErr bool
+	}{
+		{name: "no hourly factors", profile: `{"peak_multiplier":3,"burst_minutes":10}`},
+		{name: "daily", profile: encode(map[string]any{"hourly_factors": factors(HoursPerDay)})},
+		{name: "weekly", profile: encode(map[string]any{"hourly_factors": factors(HoursPerWeek)})},
+		{name: "a few hours", profile: encode(map[string]any{"hourly_factors": factors(3)}), wantErr: true},
+		{name: "a day and an hour", profile: encode(map[string]any{"hourly_factors": factors(HoursPerDay + 1)}), wantErr: true},
+		{name: "negative factor", profile: encode(map[string]any{"hourly_factors": append(factors(HoursPerDay-1), -1)}), wantErr: true},
+		{name: "negative multiplier", profile: `{"peak_multiplier":-2}`, wantErr: true},
+		{name: "negative burst", profile: `{"peak_multiplier":2,"burst_minutes":-5}`, wantErr: true},
+	}
+	for _, test := range tests {
+		var workload Workload
+		err := json.Unmarshal([]byte(`{"profile":`+test.profile+`}`), &workload)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: error = %v, want error %v", test.name, err, test.wantErr)
+		}
+	}
+}
@@ -1,21 +1,103 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // ServiceGroup represents a group of services, their node count, and disk type
 type ServiceGroup struct {
 	Name      		string   		`json:"name"`
 	Services  		[]string 		`json:"services"`
 	NoOfNodes 		int64    		`json:"no_of_nodes"`
 	DiskType  		string   		`json:"disk_type"`
+	MemoryAllocation 	string 	`json:"memory_allocation"`		// "equal" (default) splits the memory equally among the services, "demand" by their estimates
+	FailureTolerance 	string 	`json:"failure_tolerance"`		// "none" (default), "n+1", "n+2" or "az", the remaining nodes must meet the demand
+	AvailabilityZones 	int64 	`json:"availability_zones"`		// server groups the nodes are spread over, 3 for "az" tolerance when unset
 }
 
 // Dataset represents the dataset characteristics for estimation
 type Dataset struct {
 	NoOfDocuments                    		 		int64 		`json:"no_of_documents"`
-	AverageDocumentSize              		 		int64 		`json:"average_document_size"`
+	AverageDocumentSize              		 		ByteSize 	`json:"average_document_size"`				// plain numbers in size_unit
 	ResidentRatio                    		 		int64 		`json:"resident_ratio"`
 	PercentIndexesOfDataset          		 		int64 		`json:"percent_indexes_of_dataset"`
 	PercentFullTextSearchOfDataset   		 		int64 		`json:"percent_full_text_search_of_dataset"`
 	PercentOperationalAnalyticsOfDataset 		int64 		`json:"percent_operational_analytics_of_dataset"`
+	AverageKeySize                   		 		ByteSize 	`json:"average_key_size"`							// plain numbers in bytes
+	CompressionRatio                 		 		*float64 	`json:"compression_ratio,omitempty"`	// share of the document size saved by Snappy, unset uses the default
+	AverageFieldLength               		 		ByteSize 	`json:"average_field_length"`				// text indexed by Search per document, plain numbers in bytes
+	AnalyticsDatasets                		 		[]AnalyticsDataset 	`json:"analytics_datasets"`
+	AccessPattern                    		 		AccessPattern 	`json:"access_pattern"`
+	DocumentSizeHistogram            		 		[]DocumentSizeBucket 			`json:"document_size_histogram"`			// replaces average_document_size when set
+	DocumentSizePercentiles          		 		[]DocumentSizePercentile 	`json:"document_size_percentiles"`		// replaces average_document_size when set
+	SizeUnit                         		 		string 		`json:"size_unit,omitempty"`							// unit of plain document size numbers, "KB" when unset, document_size_unit is accepted too
+	NoOfScopes                       		 		int64 		`json:"no_of_scopes"`
+	NoOfCollections                  		 		int64 		`json:"no_of_collections"`
+	Collections                      		 		[]Collection 	`json:"collections"`									// optional per-collection overrides
+	Bucket                           		 		BucketSettings 	`json:"bucket"`
+}
+
+// BucketSettings represents the settings of the bucket holding the dataset
+type BucketSettings struct {
+	NumReplicas     		*int64 		`json:"num_replicas,omitempty"`		// unset uses 1 replica
+	DurabilityLevel 		string 		`json:"durability_level"`					// "none", "majority", "majorityAndPersistActive" or "persistToMajority"
+	Compaction      		CompactionSettings 	`json:"compaction"`
+	PurgeIntervalDays 	float64 	`json:"purge_interval_days"`					// metadata purge interval, 3 days when unset
+}
+
+// CompactionSettings represents the auto-compaction settings of the bucket
+type CompactionSettings struct {
+	FragmentationThresholdPercent      		float64 		`json:"fragmentation_threshold_percent"`				// 30% when unset
+	IndexFragmentationThresholdPercent 		float64 		`json:"index_fragmentation_threshold_percent"`	// 30% when unset
+	WindowHours                        		float64 		`json:"window_hours"`													// hours per day compaction may run, 24 when unset
+	Parallelism                        		int64   		`json:"parallelism"`													// vBucket files compacted at the same time, 1 when unset
+}
+
+// Collection represents a collection of the bucket, its documents are part of the dataset's documents
+type Collection struct {
+	Name                		string   		`json:"name"`
+	Scope               		string   		`json:"scope"`
+	NoOfDocuments       		int64    		`json:"no_of_documents"`
+	AverageDocumentSize 		ByteSize 		`json:"average_document_size"`		// plain numbers in size_unit, 0 uses the dataset's size
+}
+
+// DocumentSizeBucket represents a bucket of the document size histogram
+type DocumentSizeBucket struct {
+	Size             		ByteSize 		`json:"size"`									// plain numbers in size_unit
+	Percent          		float64 		`json:"percent"`								// % of the documents in the bucket
+	CompressionRatio 		*float64 		`json:"compression_ratio,omitempty"`		// share of the size saved by compression, unset uses the dataset's ratio
+}
+
+// DocumentSizePercentile represents a percentile of the document sizes
+type DocumentSizePercentile struct {
+	Percentile 		float64 		`json:"percentile"`
+	Size       		ByteSize 		`json:"size"`										// plain numbers in size_unit
+}
+
+// AccessPattern describes how reads are skewed over the documents, used to recommend the resident ratio
+type AccessPattern struct {
+	Model                  		string  		`json:"model"`												// "hot_set", "zipfian" or "recent_data", empty for uniform access
+	HotSetPercent          		float64 		`json:"hot_set_percent"`							// hot_set: % of documents in the hot set
+	HotSetReadPercent      		float64 		`json:"hot_set_read_percent"`					// hot_set: % of reads hitting the hot set
+	ZipfExponent           		float64 		`json:"zipf_exponent"`								// zipfian: skew of the document popularity
+	RecentDays             		float64 		`json:"recent_days"`									// recent_data: age of the data considered recent
+	PercentReadsRecent     		float64 		`json:"percent_reads_recent"`					// recent_data: % of reads hitting the recent data
+	TargetCacheMissPercent 		float64 		`json:"target_cache_miss_percent"`		// highest acceptable % of reads missing the cache
+}
+
+// AnalyticsDataset represents an Analytics dataset shadowing (a filtered part of) a Data collection
+type AnalyticsDataset struct {
+	Name             		string           		`json:"name"`
+	SourceCollection 		string           		`json:"source_collection"`
+	FilterPercent    		float64          		`json:"filter_percent"`				// % of the source collection's documents in the dataset
+	SecondaryIndexes 		[]AnalyticsIndex 		`json:"secondary_indexes"`
+}
+
+// AnalyticsIndex represents a secondary index on an Analytics dataset
+type AnalyticsIndex struct {
+	Name     		string 		`json:"name"`
+	KeySize  		ByteSize 		`json:"key_size"`							// size of the secondary key, plain numbers in bytes, key_bytes is accepted too
 }
 
 // Workload represents the workload characteristics for estimation
@@ -24,6 +106,52 @@ type Workload struct {
 	WritesPerSec     		int64 				`json:"writes_per_sec"`
 	DeletesPerSec    		int64 				`json:"deletes_per_sec"`
 	SQLQueriesPerSec 		int64 				`json:"sql_queries_per_sec"`
+
+	// Analytics workload (ingestion defaults to the mutation rate of the linked Data collections)
+	AnalyticsConcurrentQueries 	int64 		`json:"analytics_concurrent_queries"`
+	AnalyticsQueryComplexity   	string 		`json:"analytics_query_complexity"`		// "simple", "medium" or "complex"
+	AnalyticsIngestionPerSec   	int64 		`json:"analytics_ingestion_per_sec"`
+
+	// Multi-document ACID transactions (their reads and writes come on top of the rates above)
+	TransactionsPerSec         	int64 		`json:"transactions_per_sec"`
+	DocumentsPerTransaction    	int64 		`json:"documents_per_transaction"`
+	PercentTransactionWrites   	*int64 		`json:"percent_transaction_writes,omitempty"`		// % of the documents of a transaction that are written, the rest is read, unset writes every document
+
+	// Daily or weekly profile, the rates above are averages
+	Profile                    	*WorkloadProfile 	`json:"profile,omitempty"`
+}
+
+// WorkloadProfile describes how the workload varies around its average rates, by hour or by a peak multiplier
+type WorkloadProfile struct {
+	HourlyFactors  			[]float64 		`json:"hourly_factors"`				// load of every hour of a day (24) or week (168), relative to each other
+	PeakMultiplier 			float64   		`json:"peak_multiplier"`			// peak to average ratio of the rates, used without hourly factors
+	BurstMinutes   			float64   		`json:"burst_minutes"`				// how long the peak lasts, unset is sustained
+}
+
+// Lengths of the hourly factors of a profile
+const (
+	HoursPerDay  = 24
+	HoursPerWeek = 168
+)
+
+// UnmarshalJSON decodes the profile, hourly factors must cover a day or a week and cannot be negative
+func (p *WorkloadProfile) UnmarshalJSON(data []byte) error {
+	type profileFields WorkloadProfile
+	if err := json.Unmarshal(data, (*profileFields)(p)); err != nil {
+		return err
+	}
+	if n := len(p.HourlyFactors); n != 0 && n != HoursPerDay && n != HoursPerWeek {
+		return fmt.Errorf("hourly_factors must hold %d (daily) or %d (weekly) values, got %d", HoursPerDay, HoursPerWeek, n)
+	}
+	for hour, factor := range p.HourlyFactors {
+		if factor < 0 {
+			return fmt.Errorf("hourly_factors[%d] cannot be negative", hour)
+		}
+	}
+	if p.PeakMultiplier < 0 || p.BurstMinutes < 0 {
+		return fmt.Errorf("peak_multiplier and burst_minutes cannot be negative")
+	}
+	return nil
 }
 
 // ComputeRequest is the input request format for the workload estimation
@@ -32,6 +160,40 @@ type ComputeRequest struct {
 	Dataset       		Dataset        	`json:"dataset"`
 	Workload      		Workload       	`json:"workload"`
 	WorkloadNature 		string        	`json:"workload_nature"`
+	Backup        		BackupPolicy   	`json:"backup"`
+	SyncGateway   		SyncGatewayWorkload 	`json:"sync_gateway"`
+	DCPConsumers  		DCPConsumers   	`json:"dcp_consumers"`
+	TargetUtilization 	Utilization 	`json:"target_utilization"`		// share of every node the demand may use (In %), RAM defaults to 80 and the rest to 100
+}
+
+// DCPConsumers counts the consumers streaming the mutations of the bucket over DCP.
+// Index, Search, Eventing and Analytics services of the request without a count open a single consumer.
+type DCPConsumers struct {
+	Indexes           		int64 		`json:"indexes"`
+	SearchIndexes     		int64 		`json:"search_indexes"`
+	EventingFunctions 		int64 		`json:"eventing_functions"`
+	AnalyticsLinks    		int64 		`json:"analytics_links"`
+	XDCRReplications  		int64 		`json:"xdcr_replications"`
+}
+
+// SyncGatewayWorkload represents the mobile clients synced through Sync Gateway / App Services
+type SyncGatewayWorkload struct {
+	ConnectedDevices           		int64 		`json:"connected_devices"`
+	ChangesFeedPerSec          		int64 		`json:"changes_feed_per_sec"`				// changes delivered to clients per second
+	ChannelsPerUser            		int64 		`json:"channels_per_user"`
+	PercentImportProcessing    		int64 		`json:"percent_import_processing"`		// % of Data writes made through SDKs and imported by Sync Gateway
+	PercentDocumentsWithAttachments 	int64 	`json:"percent_documents_with_attachments"`
+	AverageAttachmentSize      		ByteSize 	`json:"average_attachment_size"`				// plain numbers in KB
+}
+
+// BackupPolicy represents the schedule, retention and merge policy of the Backup service
+type BackupPolicy struct {
+	FullBackupIntervalDays         		int64 		`json:"full_backup_interval_days"`
+	IncrementalBackupIntervalHours 		int64 		`json:"incremental_backup_interval_hours"`
+	RetentionDays                  		int64 		`json:"retention_days"`
+	MergeIntervalDays              		int64 		`json:"merge_interval_days"`			// 0 keeps every incremental backup until it expires
+	BackupWindowHours              		int64 		`json:"backup_window_hours"`
+	Compression                    		bool  		`json:"compression"`
 }
 
 // Summary holds the overview of the estimations of all the service groups
@@ -41,21 +203,142 @@ type Summary struct {
 	ServiceGroups				int64					`json:"service_groups"`
 	Services 						[]string			`json:"services"`
 	WorkloadType				string				`json:"workload_type"`
+	Total								Resources			`json:"total"`														// across all the nodes of the cluster
+}
+
+// Resources holds an amount of every resource, in the units of ResultUnits
+type Resources struct {
+	RAM									int64					`json:"ram"`
+	CPU									int64					`json:"cpu"`
+	Disk								int64					`json:"disk"`
+	DiskIO							int64					`json:"disk_io"`													// provisioned IOPS
+}
+
+// Scale returns the resources of the given number of nodes
+func (r Resources) Scale(nodes int64) Resources {
+	return Resources{RAM: r.RAM * nodes, CPU: r.CPU * nodes, Disk: r.Disk * nodes, DiskIO: r.DiskIO * nodes}
+}
+
+// Add returns the sum of both resources
+func (r Resources) Add(other Resources) Resources {
+	return Resources{RAM: r.RAM + other.RAM, CPU: r.CPU + other.CPU, Disk: r.Disk + other.Disk, DiskIO: r.DiskIO + other.DiskIO}
 }
 
-// ServiceGroupResult holds the resource estimates for each service group
+// ServiceGroupResult holds the resource estimates for each service group, in the units of ResultUnits
 type ServiceGroupResult struct {
 	Services 				 		[]string  		`json:"services"`
 	Nodes 					 		int64 				`json:"nodes"`
-	EstimatedRAM     		int64  				`json:"estimated_ram"`
-	EstimatedCPU     		int64  				`json:"estimated_cpu"`
+	EstimatedRAM     		int64  				`json:"estimated_ram"`						// GiB per node
+	EstimatedCPU     		int64  				`json:"estimated_cpu"`						// vCPU per node
 	DiskType						string 				`json:"disk_type"`
-	EstimatedDisk    		int64  				`json:"estimated_disk"`
-	EstimatedDiskIO  		int64  				`json:"estimated_disk_io"`
+	EstimatedDisk    		int64  				`json:"estimated_disk"`						// GiB per node
+	EstimatedDiskIO  		int64  				`json:"estimated_disk_io"`				// IOPS per node
+	WriteLatencyMs   		float64 			`json:"write_latency_ms,omitempty"`	// estimated Data write latency at the bucket's durability level
+	CompactionThroughput 	float64 		`json:"compaction_throughput,omitempty"`	// MB/s per node read and written by Data compactions while they run
+	ServiceQuotas    		[]ServiceQuota 	`json:"service_quotas"`					// per node
+	OSReservedRAM    		float64 			`json:"os_reserved_ram"`					// GiB per node kept out of the service quotas
+	PerNode          		Resources 		`json:"per_node"`
+	Total            		Resources 		`json:"total"`										// across the nodes of the group
+	FailureTolerance 		string 				`json:"failure_tolerance,omitempty"`
+	NodesTolerated   		int64 				`json:"nodes_tolerated"`							// nodes the group may lose while meeting its demand
+	TargetUtilization 	Utilization 	`json:"target_utilization"`					// the nodes are sized for
+	Utilization      		Utilization 	`json:"utilization"`									// at the average workload with every node running
+	PeakUtilization  		Utilization 	`json:"peak_utilization"`							// at the peak workload with every node running
+	DegradedUtilization 	Utilization `json:"degraded_utilization"`				// at the peak workload after losing the tolerated nodes
+}
+
+// Utilization holds the share of the provisioned resources the demand uses (In %)
+type Utilization struct {
+	RAM     						float64 			`json:"ram"`
+	CPU     						float64 			`json:"cpu"`
+	Disk    						float64 			`json:"disk"`
+	DiskIO  						float64 			`json:"disk_io"`
+}
+
+// ServiceQuota holds the resources per node of a single service of a group, query has no memory quota
+type ServiceQuota struct {
+	Service 						string 				`json:"service"`
+	RAM     						float64 			`json:"ram"`												// GiB
+	CPU     						float64 			`json:"cpu"`												// vCPU
+	Disk    						float64 			`json:"disk"`											// GiB
+	DiskIO  						float64 			`json:"disk_io"`										// IOPS
+}
+
+// ResultUnits names the units of the resource figures in the response
+type ResultUnits struct {
+	RAM									string				`json:"ram"`
+	CPU									string				`json:"cpu"`
+	Disk								string				`json:"disk"`
+	DiskIO							string				`json:"disk_io"`
+}
+
+// DefaultResultUnits are the units the estimators report in
+var DefaultResultUnits = ResultUnits{RAM: "GiB", CPU: "vCPU", Disk: "GiB", DiskIO: "IOPS"}
+
+// ResidentRatioPoint holds the Data RAM and cache misses at a given resident ratio
+type ResidentRatioPoint struct {
+	ResidentRatio					int64					`json:"resident_ratio"`
+	DataRAM								int64					`json:"data_ram"`										// in GiB, for the whole cluster
+	CacheMissPercent			float64				`json:"cache_miss_percent"`
+	DiskReadIOPS					int64					`json:"disk_read_iops"`
+}
+
+// WorkingSetResult holds the resident ratio recommended from the access pattern and its trade-offs
+type WorkingSetResult struct {
+	RecommendedResidentRatio		int64								`json:"recommended_resident_ratio"`
+	CacheMissPercent						float64							`json:"cache_miss_percent"`
+	TradeOffs										[]ResidentRatioPoint	`json:"trade_offs"`
 }
 
 // ComputeResponse is the output response format containing results for all service groups
 type ComputeResponse struct {
 	Summary							  Summary								`json:"summary"`
 	ServiceGroupsResults  []ServiceGroupResult 	`json:"service_groups_results"`
+	WorkingSet						*WorkingSetResult			`json:"working_set,omitempty"`
+	Units									ResultUnits						`json:"units"`
+	Tombstones						*TombstoneResult			`json:"tombstones,omitempty"`
+	InterZoneTraffic			*InterZoneTraffic			`json:"inter_zone_traffic,omitempty"`
+	WorkloadProfile				*ProfileResult				`json:"workload_profile,omitempty"`
+	Warnings							[]Warning							`json:"warnings,omitempty"`
+	Blocked								bool									`json:"blocked"`												// a blocking guardrail fails, the estimate cannot be deployed
+}
+
+// Severities of the warnings
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"  // the estimate cannot be met as requested
+)
+
+// Warning reports an input or an estimate that exceeds a limit or guardrail
+type Warning struct {
+	Code        				string 				`json:"code"`
+	Severity    				string 				`json:"severity"`
+	Group       				string 				`json:"group,omitempty"`								// name or services of the affected group
+	Service     				string 				`json:"service,omitempty"`
+	Message     				string 				`json:"message"`
+	Remediation 				string 				`json:"remediation,omitempty"`
+}
+
+// ProfileResult reports the peak of the workload profile CPU and disk IO are sized for
+type ProfileResult struct {
+	PeakFactor						float64				`json:"peak_factor"`												// peak rates relative to the average
+	CPUPeakFactor					float64				`json:"cpu_peak_factor"`										// peak CPU is sized for, a short burst is spread over 5 minutes
+	DiskIOPeakFactor			float64				`json:"disk_io_peak_factor"`								// peak disk IO is sized for, a short burst is spread over 15 minutes
+	PeakHour							*int64				`json:"peak_hour,omitempty"`								// hour of the day or week the peak falls in
+	BurstMinutes					float64				`json:"burst_minutes,omitempty"`
+}
+
+// InterZoneTraffic reports the traffic between the availability zones of the Data service
+type InterZoneTraffic struct {
+	AvailabilityZones			int64					`json:"availability_zones"`
+	Replication						float64				`json:"replication"`												// MB/s sent to replicas in other zones
+	DCP										float64				`json:"dcp"`																// MB/s streamed to consumers in other zones
+	MonthlyTransfer				float64				`json:"monthly_transfer"`										// GB per month crossing zones
+}
+
+// TombstoneResult reports the space held by the tombstones of deleted documents
+type TombstoneResult struct {
+	PurgeIntervalDays			float64				`json:"purge_interval_days"`
+	DiskSpace							float64				`json:"disk_space"`													// GiB across the cluster, part of the Data disk estimate
 }
\ No newline at end of file
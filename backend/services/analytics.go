@@ -7,35 +7,113 @@ import (
 
 // EstimateResourcesForAnalytics calculates resources required for the Analytics service.
 func EstimateResourcesForAnalytics(dataset models.Dataset, workload models.Workload) (ram, cpu, disk, diskIO float64) {
-	ram = CalculateAnalyticsRAM()
-	cpu = CalculateAnalyticsCPU()
+	cpu = CalculateAnalyticsCPU(dataset, workload)
+	ram = CalculateAnalyticsRAM(dataset, workload, cpu)
 	disk = CalculateAnalyticsDisk(dataset)
-	diskIO = CalculateAnalyticsDiskIO()
+	diskIO = CalculateAnalyticsDiskIO(dataset, workload)
 
 	return ram, cpu, disk, diskIO
 }
 
-// CalculateAnalyticsRAM estimates the RAM required for the Analytics service. (verified)
-func CalculateAnalyticsRAM() float64 {
-	return 0
+// CalculateAnalyticsRAM estimates the RAM required for the Analytics service.
+func CalculateAnalyticsRAM(dataset models.Dataset, workload models.Workload, cpuAvailable float64) float64 {
+	// Constants
+	const bucketTypeCouchbase = 56
+	const bufferCacheRatio = 0.2										// share of the analytics datasets kept in the buffer cache
+	const operatorMemory = 32 * 1024 * 1024						// compiler sort / join / group memory per operator per partition (In bytes)
+	const memoryComponentFlushSeconds = 30.0					// seconds of ingested mutations held in memory components before a flush
+	const memoryComponentBudget = 256 * 1024 * 1024		// upper bound of the memory components per partition (In bytes)
+	const jvmOverhead = 1.25												// heap overhead of the analytics JVM
+	const minimumAnalyticsQuota = 1.0								// minimum analytics memory quota (In GB)
+
+	// Step 1: Calculate size of the analytics datasets (In bytes)
+	analyticsDataSize := analyticsDocuments(dataset) * float64(dataset.AverageKeySize + dataset.AverageDocumentSize + bucketTypeCouchbase)
+
+	// Step 2: Buffer cache for the analytics collections (In bytes)
+	bufferCache := analyticsDataSize * bufferCacheRatio
+
+	// Step 3: Sort / join / group memory for concurrent queries (In bytes)
+	// every partition (one per core) of a query gets its own operator memory
+	partitions := math.Max(cpuAvailable, 1)
+	queryMemory := float64(workload.AnalyticsConcurrentQueries) * analyticsOperatorsPerQuery(workload.AnalyticsQueryComplexity) * operatorMemory * partitions
+
+	// Step 4: Memory components holding ingested mutations before they are flushed (In bytes)
+	ingestionMemory := analyticsIngestionRate(dataset, workload) * float64(dataset.AverageDocumentSize) * memoryComponentFlushSeconds
+	ingestionMemory = math.Min(ingestionMemory, memoryComponentBudget * partitions)
+
+	// Step 5: Total memory including JVM overhead (In GB)
+	totalMemory := (bufferCache + queryMemory + ingestionMemory) * jvmOverhead / 1024 / 1024 / 1024
+
+	// Step 6: Recommended RAM quota (In GB)
+	recommendedRamQuota := math.Ceil(max(totalMemory, minimumAnalyticsQuota))
+
+	return recommendedRamQuota
+}
+
+// CalculateAnalyticsCPU estimates the CPU required for the Analytics service.
+func CalculateAnalyticsCPU(dataset models.Dataset, workload models.Workload) float64 {
+	// Constants
+	const ingestionThroughputPerCore = 10000.0				// mutations per second a single ingestion partition keeps up with
+
+	// Step 1: Calculate cores for ingesting mutations from the linked Data collections
+	ingestionCoresReq := analyticsIngestionRate(dataset, workload) / ingestionThroughputPerCore
+
+	// Step 2: Calculate cores for query execution
+	queryCoresReq := float64(workload.AnalyticsConcurrentQueries) * analyticsCoresPerQuery(workload.AnalyticsQueryComplexity)
+
+	// Step 3: Analytics Cores Required
+	analyticsCoresReq := ingestionCoresReq + queryCoresReq
+
+	// Step 4: Recommended Cores
+	recommendedCores := math.Ceil(max(analyticsCoresReq * 1.2, 1))
+
+	return recommendedCores
 }
 
-// CalculateAnalyticsCPU estimates the CPU required for the Analytics service. (verified)
-func CalculateAnalyticsCPU() float64 {
-	return 0
+// analyticsIngestionRate returns the mutations per second ingested by the Analytics service.
+// Falls back to the share of Data mutations that belongs to the analytics datasets.
+func analyticsIngestionRate(dataset models.Dataset, workload models.Workload) float64 {
+	if workload.AnalyticsIngestionPerSec > 0 {
+		return float64(workload.AnalyticsIngestionPerSec)
+	}
+	mutationRatePerSec := float64(workload.WritesPerSec) + float64(workload.DeletesPerSec)
+	return mutationRatePerSec * analyticsDocuments(dataset) / math.Max(float64(dataset.NoOfDocuments), 1)
+}
+
+// analyticsOperatorsPerQuery returns the number of memory intensive operators (sort, join, group) of a query
+func analyticsOperatorsPerQuery(complexity string) float64 {
+	switch complexity {
+	case "simple":
+		return 1
+	case "complex":
+		return 6
+	default: // "medium"
+		return 3
+	}
+}
+
+// analyticsCoresPerQuery returns the cores kept busy by a single running query
+func analyticsCoresPerQuery(complexity string) float64 {
+	switch complexity {
+	case "simple":
+		return 0.5
+	case "complex":
+		return 2
+	default: // "medium"
+		return 1
+	}
 }
 
-// CalculateAnalyticsDisk estimates the Disk required for the Analytics service. (verified)
+// CalculateAnalyticsDisk estimates the Disk required for the Analytics service from its datasets and secondary indexes.
 func CalculateAnalyticsDisk(dataset models.Dataset) float64 {
-	const avgKeySize = 0
 	const storageEngine = "Couchstore"  								// or "Magma"
 	const bucketType = "Couchbase"
 	const bucketTypeCouchbase = 56
 	const bucketTypeEphemeral = 72
 	const numReplicas = 1																// under advanced section in sizing calculator
 	const queryTempSpaceAllowance = 2										// under advance section in sizing calculator - by default kept as 2
-	const percentageDocumentsInIndex = 100.0						// under advance section in sizing calculator - by default kept as 100
-	const totalSecondaryBytes = 0												// under advance section in sizing calculator - by default kept as 0
+	const percentageDocumentsInIndex = 100.0						// analytics indexes cover every document of their dataset
+	avgKeySize := dataset.AverageKeySize
 
 	// Step 1: Calculate data collection disk space required (In GB)
 	var dataCollectionDiskSpaceRequired float64 = 0.0
@@ -49,31 +127,106 @@ func CalculateAnalyticsDisk(dataset models.Dataset) float64 {
 		}
 	}
 
-	// Step 2: Calculate no of documents in analytics collection
-	var documentsInAnalyticsCollection float64 = float64(dataset.PercentOperationalAnalyticsOfDataset) * float64(dataset.NoOfDocuments) / 100
+	var totalDisk float64 = 0.0
+	for _, analyticsDataset := range analyticsDatasets(dataset) {
+		// Step 2: Calculate no of documents in analytics collection
+		sourceDocuments := collectionDocuments(dataset, analyticsDataset.SourceCollection)
+		var documentsInAnalyticsCollection float64 = analyticsDataset.FilterPercent * sourceDocuments / 100
 
-	// Step 3: Calculate active data size (In GB)
-	var activeDataSize float64 = dataCollectionDiskSpaceRequired * float64(dataset.PercentOperationalAnalyticsOfDataset) / 100
+		// Step 3: Calculate active data size (In GB), scaled to the source collection's share of the bucket
+		sourceShare := sourceDocuments / math.Max(float64(dataset.NoOfDocuments), 1)
+		var activeDataSize float64 = dataCollectionDiskSpaceRequired * sourceShare * analyticsDataset.FilterPercent / 100
 
-	// Step 4: Calculate replica data size (In GB)
-	var replicaDataSize float64 = activeDataSize * numReplicas
+		// Step 4: Calculate replica data size (In GB)
+		var replicaDataSize float64 = activeDataSize * numReplicas
 
-	// Step 5: Active, Replica and Temp total (In GB)
-	var activeReplicaTempTotal float64 = ((activeDataSize * queryTempSpaceAllowance) + replicaDataSize)
+		// Step 5: Active, Replica and Temp total (In GB)
+		var activeReplicaTempTotal float64 = ((activeDataSize * queryTempSpaceAllowance) + replicaDataSize)
 
-	// Step 6: Calculate number of documents in Analytics index
-	var documentsInAnalyticsIndex float64 = percentageDocumentsInIndex * documentsInAnalyticsCollection / 100
+		// Step 6: Calculate number of documents in Analytics index
+		var documentsInAnalyticsIndex float64 = percentageDocumentsInIndex * documentsInAnalyticsCollection / 100
 
-	// Step 7: Calculate size of index (In GB)
-	var indexSize float64 = round((documentsInAnalyticsIndex * (avgKeySize + totalSecondaryBytes) * (1 + numReplicas)) / 1024 / 1024 / 1024, 1)
+		// Step 7: Calculate size of the secondary indexes (In GB)
+		var indexSize float64 = 0.0
+		for _, index := range analyticsDataset.SecondaryIndexes {
+			indexSize += Round((documentsInAnalyticsIndex * float64(avgKeySize + index.KeySize) * (1 + numReplicas)) / 1024 / 1024 / 1024, 1)
+		}
+
+		totalDisk += activeReplicaTempTotal + indexSize
+	}
 
 	// Step 8: Total analytics disk size (In GB)
-	var totalDisk float64 = round(activeReplicaTempTotal + indexSize, 0)
+	totalDisk = Round(totalDisk, 0)
 
 	return totalDisk
 }
 
-// CalculateAnalyticsDiskIO estimates the Disk IO required for the Analytics service. (verified)
-func CalculateAnalyticsDiskIO() float64 {
-	return 0
+// CalculateAnalyticsDiskIO estimates the Disk IO required for the Analytics service.
+func CalculateAnalyticsDiskIO(dataset models.Dataset, workload models.Workload) float64 {
+	// Constants
+	const numReplicas = 1
+	const lsmMergeAmplification = 3.0								// times an ingested record is rewritten by LSM merges
+	const pageSize = 128 * 1024											// buffer cache page size (In bytes)
+	avgKeySize := float64(dataset.AverageKeySize)
+
+	// Step 1: Share of the ingestion going to each analytics dataset and bytes written per ingested record
+	var totalFilterPercent, bytesPerRecord float64
+	datasets := analyticsDatasets(dataset)
+	for _, analyticsDataset := range datasets {
+		totalFilterPercent += analyticsDataset.FilterPercent
+	}
+	for _, analyticsDataset := range datasets {
+		if totalFilterPercent == 0 {
+			break
+		}
+		recordBytes := avgKeySize + float64(dataset.AverageDocumentSize)
+		for _, index := range analyticsDataset.SecondaryIndexes {
+			recordBytes += avgKeySize + float64(index.KeySize)
+		}
+		bytesPerRecord += recordBytes * analyticsDataset.FilterPercent / totalFilterPercent
+	}
+
+	// Step 2: Ingestion write IOPS including replicas and LSM merges
+	ingestionBytesPerSec := analyticsIngestionRate(dataset, workload) * bytesPerRecord * (1 + numReplicas) * lsmMergeAmplification
+	ingestionIOPS := ingestionBytesPerSec / pageSize
+
+	// Step 3: Read IOPS of the running queries
+	queryIOPS := float64(workload.AnalyticsConcurrentQueries) * analyticsScanIOPSPerQuery(workload.AnalyticsQueryComplexity)
+
+	// Step 4: Total disk IO
+	diskIO := math.Ceil(ingestionIOPS + queryIOPS)
+
+	return diskIO
+}
+
+// analyticsDatasets returns the analytics datasets of the request.
+// Without explicit definitions a single unindexed dataset covering PercentOperationalAnalyticsOfDataset is assumed.
+func analyticsDatasets(dataset models.Dataset) []models.AnalyticsDataset {
+	if len(dataset.AnalyticsDatasets) > 0 {
+		return dataset.AnalyticsDatasets
+	}
+	return []models.AnalyticsDataset{
+		{Name: "default", FilterPercent: float64(dataset.PercentOperationalAnalyticsOfDataset)},
+	}
+}
+
+// analyticsDocuments returns the number of documents held by all analytics datasets
+func analyticsDocuments(dataset models.Dataset) float64 {
+	var documents float64
+	for _, analyticsDataset := range analyticsDatasets(dataset) {
+		documents += analyticsDataset.FilterPercent * collectionDocuments(dataset, analyticsDataset.SourceCollection) / 100
+	}
+	return documents
+}
+
+// analyticsScanIOPSPerQuery returns the read IOPS of a single running query missing the buffer cache
+func analyticsScanIOPSPerQuery(complexity string) float64 {
+	switch complexity {
+	case "simple":
+		return 100
+	case "complex":
+		return 500
+	default: // "medium"
+		return 250
+	}
 }
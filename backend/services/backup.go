@@ -0,0 +1,154 @@
+package services
+
+import (
+	"math"
+	"workload-estimator-poc/models"
+)
+
+// EstimateResourcesForBackup calculates resources required for the Backup service.
+func EstimateResourcesForBackup(dataset models.Dataset, workload models.Workload, policy models.BackupPolicy) (ram, cpu, disk, diskIO float64) {
+	cpu = CalculateBackupCPU(dataset, policy)
+	ram = CalculateBackupRAM(cpu)
+	disk = CalculateBackupDisk(dataset, workload, policy)
+	diskIO = CalculateBackupDiskIO(dataset, workload, policy)
+
+	return ram, cpu, disk, diskIO
+}
+
+// CalculateBackupRAM estimates the RAM required for the Backup service.
+func CalculateBackupRAM(cpuAvailable float64) float64 {
+	// Constants
+	const baseMemory = 1.0							// backup service and cbbackupmgr process (In GB)
+	const memoryPerThread = 0.5					// transfer buffers of a single backup thread (In GB)
+
+	// Step 1: One backup thread per core
+	threads := math.Max(cpuAvailable, 1)
+
+	// Step 2: Recommended RAM (In GB)
+	recommendedRam := math.Ceil(baseMemory + threads*memoryPerThread)
+
+	return recommendedRam
+}
+
+// CalculateBackupCPU estimates the CPU required for the Backup service.
+func CalculateBackupCPU(dataset models.Dataset, policy models.BackupPolicy) float64 {
+	// Constants
+	const throughputPerCore = 100 * 1024 * 1024				// bytes per second a single backup thread streams (In bytes)
+	const compressionCpuFactor = 1.5										// extra CPU spent compressing the backup
+
+	// Step 1: Throughput needed to finish a full backup within the backup window (In bytes per second)
+	throughput := backupDatasetSize(dataset) / backupWindowSeconds(policy)
+
+	// Step 2: Cores Required
+	coresReq := throughput / throughputPerCore
+	if policy.Compression {
+		coresReq *= compressionCpuFactor
+	}
+
+	// Step 3: Recommended Cores
+	recommendedCores := math.Ceil(max(coresReq*1.2, 1))
+
+	return recommendedCores
+}
+
+// CalculateBackupDisk estimates the repository disk space required over the retention window.
+func CalculateBackupDisk(dataset models.Dataset, workload models.Workload, policy models.BackupPolicy) float64 {
+	// Step 1: Size of a full backup (In bytes)
+	fullBackupSize := backupDatasetSize(dataset) * backupCompressionFactor(policy)
+
+	// Step 2: Size of an incremental backup (In bytes)
+	incrementalBackupSize := backupIncrementalSize(dataset, workload, policy)
+
+	// Step 3: Number of full and incremental backups retained
+	// one extra full backup is kept until the oldest chain expires
+	fullBackupsRetained := math.Ceil(float64(policy.RetentionDays)/float64(policy.FullBackupIntervalDays)) + 1
+	backupsInRetention := float64(policy.RetentionDays) * 24 / float64(policy.IncrementalBackupIntervalHours)
+	incrementalBackupsRetained := math.Max(backupsInRetention-fullBackupsRetained, 0)
+
+	// Step 4: Space taken by the incremental backups (In bytes)
+	var incrementalSpace float64
+	var mergeScratchSpace float64
+	if backupMerges(policy) {
+		// incrementals older than the merge interval are merged into one backup per merge interval
+		backupsPerMerge := float64(policy.MergeIntervalDays) * 24 / float64(policy.IncrementalBackupIntervalHours)
+		mergedBackupSize := backupMergedSize(dataset, workload, policy)
+		mergedBackups := math.Ceil(float64(policy.RetentionDays-policy.MergeIntervalDays) / float64(policy.MergeIntervalDays))
+		unmergedBackups := math.Min(backupsPerMerge, incrementalBackupsRetained)
+
+		incrementalSpace = unmergedBackups*incrementalBackupSize + mergedBackups*mergedBackupSize
+		// the merge writes the new backup before the merged ones are removed
+		mergeScratchSpace = mergedBackupSize
+	} else {
+		incrementalSpace = incrementalBackupsRetained * incrementalBackupSize
+	}
+
+	// Step 5: Total repository size (In GB)
+	repositorySize := (fullBackupsRetained*fullBackupSize + incrementalSpace + mergeScratchSpace) / 1024 / 1024 / 1024
+
+	// Step 6: Upper bound disk value
+	repositorySize = math.Ceil(repositorySize)
+
+	return repositorySize
+}
+
+// CalculateBackupDiskIO estimates the Disk I/O of the backup nodes during the backup window.
+func CalculateBackupDiskIO(dataset models.Dataset, workload models.Workload, policy models.BackupPolicy) float64 {
+	// Constants
+	const ioSize = 256 * 1024					// sequential write size of the repository (In bytes)
+
+	// Step 1: Write throughput of a full backup within the backup window (In bytes per second)
+	fullBackupThroughput := backupDatasetSize(dataset) * backupCompressionFactor(policy) / backupWindowSeconds(policy)
+
+	// Step 2: Read and write throughput of a merge within the backup window (In bytes per second)
+	var mergeThroughput float64
+	if backupMerges(policy) {
+		mergeThroughput = 2 * backupMergedSize(dataset, workload, policy) / backupWindowSeconds(policy)
+	}
+
+	// Step 3: Disk IO
+	diskIO := math.Ceil(math.Max(fullBackupThroughput, mergeThroughput) / ioSize)
+
+	return diskIO
+}
+
+// backupDatasetSize returns the uncompressed size of the documents, keys and metadata backed up (In bytes)
+func backupDatasetSize(dataset models.Dataset) float64 {
+	const bucketTypeCouchbase = 56
+	return float64(dataset.NoOfDocuments) * float64(dataset.AverageKeySize+dataset.AverageDocumentSize+bucketTypeCouchbase)
+}
+
+// backupIncrementalSize returns the size of an incremental backup (In bytes).
+// Mutations to the same document within an interval are deduplicated, so an incremental never holds more than every document once.
+func backupIncrementalSize(dataset models.Dataset, workload models.Workload, policy models.BackupPolicy) float64 {
+	const bucketTypeCouchbase = 56
+	mutationsPerInterval := float64(workload.WritesPerSec+workload.DeletesPerSec) * float64(policy.IncrementalBackupIntervalHours) * 3600
+	changedDocuments := math.Min(mutationsPerInterval, float64(dataset.NoOfDocuments))
+	return changedDocuments * float64(dataset.AverageKeySize+dataset.AverageDocumentSize+bucketTypeCouchbase) * backupCompressionFactor(policy)
+}
+
+// backupMerges reports whether incremental backups are merged, a merge interval as long as the retention merges nothing
+func backupMerges(policy models.BackupPolicy) bool {
+	return policy.MergeIntervalDays > 0 && policy.MergeIntervalDays < policy.RetentionDays
+}
+
+// backupMergedSize returns the size of the backup merging the incrementals of a merge interval (In bytes),
+// a merged backup never grows past the size of a full backup
+func backupMergedSize(dataset models.Dataset, workload models.Workload, policy models.BackupPolicy) float64 {
+	backupsPerMerge := float64(policy.MergeIntervalDays) * 24 / float64(policy.IncrementalBackupIntervalHours)
+	fullBackupSize := backupDatasetSize(dataset) * backupCompressionFactor(policy)
+	return math.Min(backupIncrementalSize(dataset, workload, policy)*backupsPerMerge, fullBackupSize)
+}
+
+// backupCompressionFactor returns the share of the data left after compressing the backup
+func backupCompressionFactor(policy models.BackupPolicy) float64 {
+	const compressionRatio = 0.5
+	if policy.Compression {
+		return 1 - compressionRatio
+	}
+	return 1
+}
+
+// backupWindowSeconds returns the length of the backup window in seconds
+func backupWindowSeconds(policy models.BackupPolicy) float64 {
+	return float64(policy.BackupWindowHours) * 3600
+}
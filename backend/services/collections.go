@@ -0,0 +1,94 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"workload-estimator-poc/models"
+)
+
+// systemScope holds the collections the cluster keeps on behalf of other components
+const systemScope = "_system"
+
+// CollectionCounts returns the user scopes and collections of the bucket.
+// Without explicit counts they are taken from the collection overrides, a bucket always has the default scope and collection.
+func CollectionCounts(dataset models.Dataset) (scopes, collections int64) {
+	scopeNames := map[string]bool{}
+	for _, collection := range dataset.Collections {
+		if strings.HasPrefix(collection.Scope, "_") && collection.Scope != "_default" {
+			continue
+		}
+		collections++
+		scopeNames[collection.Scope] = true
+	}
+	scopes = int64(len(scopeNames))
+
+	if dataset.NoOfCollections > 0 {
+		collections = dataset.NoOfCollections
+	}
+	if dataset.NoOfScopes > 0 {
+		scopes = dataset.NoOfScopes
+	}
+	return max(scopes, 1), max(collections, 1)
+}
+
+// collectionDocuments returns the documents of the named collection, or of the whole dataset when the
+// collection has no document count of its own
+func collectionDocuments(dataset models.Dataset, name string) float64 {
+	if collection, ok := findCollection(dataset, name); ok && collection.NoOfDocuments > 0 {
+		return float64(collection.NoOfDocuments)
+	}
+	return float64(dataset.NoOfDocuments)
+}
+
+// HasCollection reports whether the name, "collection" or "scope.collection", is one of the collections of the dataset
+func HasCollection(dataset models.Dataset, name string) bool {
+	_, ok := findCollection(dataset, name)
+	return ok
+}
+
+// findCollection returns the collection override of the name, "collection" or "scope.collection"
+func findCollection(dataset models.Dataset, name string) (models.Collection, bool) {
+	if name == "" {
+		return models.Collection{}, false
+	}
+	for _, collection := range dataset.Collections {
+		if collection.Name == name || collection.Scope+"."+collection.Name == name {
+			return collection, true
+		}
+	}
+	return models.Collection{}, false
+}
+
+// calculateCollectionsRAM computes the per-vBucket metadata memory of the scopes and collections (In bytes)
+func calculateCollectionsRAM(dataset models.Dataset, numReplicas float64) float64 {
+	// Constants
+	const numVBuckets = 1024
+	const memoryPerCollectionPerVBucket = 200			// collection stats, high seqno and manifest entry (In bytes)
+	const memoryPerScopePerVBucket = 64						// scope entry of the manifest (In bytes)
+
+	scopes, collections := CollectionCounts(dataset)
+
+	// every active and replica vBucket tracks every collection
+	perVBucket := float64(collections)*memoryPerCollectionPerVBucket + float64(scopes)*memoryPerScopePerVBucket
+	return perVBucket * numVBuckets * (numReplicas + 1)
+}
+
+// calculateCollectionsCPU computes the checkpoint and DCP work of the collections (In cores)
+func calculateCollectionsCPU(dataset models.Dataset) float64 {
+	// Constants
+	const collectionsPerCore = 1000.0				// collections whose checkpoints and DCP streams a single core keeps up with
+
+	// the default collection is part of the base bucket cost
+	_, collections := CollectionCounts(dataset)
+	return math.Max(float64(collections)-1, 0) / collectionsPerCore
+}
+
+// calculateCollectionsIndexRAM computes the keyspace bookkeeping the indexer keeps for every collection (In bytes)
+func calculateCollectionsIndexRAM(dataset models.Dataset) float64 {
+	// Constants
+	const memoryPerKeyspace = 1024 * 1024		// snapshot, stats and stream state of a collection (In bytes)
+
+	// the default collection is part of the base indexer cost
+	_, collections := CollectionCounts(dataset)
+	return math.Max(float64(collections)-1, 0) * memoryPerKeyspace
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"math"
+	"workload-estimator-poc/models"
+)
+
+// Compaction defaults of Couchbase Server
+const (
+	defaultFragmentationThreshold      = 30.0 // % of stale data triggering a compaction
+	defaultIndexFragmentationThreshold = 30.0
+	defaultCompactionParallelism       = 1
+)
+
+// compactionIOSize is the size of a compaction read or write (In bytes)
+const compactionIOSize = 64 * 1024
+
+// compactorThroughput is the data a single compactor copies per second (In bytes)
+const compactorThroughput = 100 * 1024 * 1024
+
+// fragmentationThreshold returns the share of stale data that triggers a Data compaction
+func fragmentationThreshold(dataset models.Dataset) float64 {
+	percent := dataset.Bucket.Compaction.FragmentationThresholdPercent
+	if percent <= 0 || percent >= 100 {
+		percent = defaultFragmentationThreshold
+	}
+	return percent / 100
+}
+
+// indexFragmentationThreshold returns the share of stale data that triggers an Index compaction
+func indexFragmentationThreshold(dataset models.Dataset) float64 {
+	percent := dataset.Bucket.Compaction.IndexFragmentationThresholdPercent
+	if percent <= 0 || percent >= 100 {
+		percent = defaultIndexFragmentationThreshold
+	}
+	return percent / 100
+}
+
+// compactionWindowHours returns the hours per day compaction may run, 24 without a window
+func compactionWindowHours(dataset models.Dataset) float64 {
+	hours := dataset.Bucket.Compaction.WindowHours
+	if hours <= 0 || hours > 24 {
+		return 24
+	}
+	return hours
+}
+
+// compactionParallelism returns the vBucket files compacted at the same time
+func compactionParallelism(dataset models.Dataset) float64 {
+	return float64(max(dataset.Bucket.Compaction.Parallelism, defaultCompactionParallelism))
+}
+
+// dataStaleBytesPerSec returns the data made stale on disk by the mutations of every copy (In bytes)
+func dataStaleBytesPerSec(dataset models.Dataset, workload models.Workload) float64 {
+	mutationsPerSec := float64(workload.WritesPerSec + workload.DeletesPerSec)
+	return mutationsPerSec * mutationBytes(dataset) * float64(BucketReplicas(dataset)+1)
+}
+
+// mutationBytes returns the size of a compressed document version with its key and metadata (In bytes)
+func mutationBytes(dataset models.Dataset) float64 {
+	const bucketTypeCouchbase = 56
+
+	compressionRatio := defaultCompressionRatio
+	if dataset.CompressionRatio != nil {
+		compressionRatio = *dataset.CompressionRatio
+	}
+	return float64(dataset.AverageDocumentSize)*(1-compressionRatio) + float64(dataset.AverageKeySize) + bucketTypeCouchbase
+}
+
+// dataDiskMultiplier returns the steady-state size on disk relative to the live data.
+// The file grows until the fragmentation threshold is reached, keeps growing outside the compaction window
+// and every vBucket file being compacted is copied next to the original.
+func dataDiskMultiplier(dataset models.Dataset, workload models.Workload, liveBytes float64) float64 {
+	threshold := fragmentationThreshold(dataset)
+	if liveBytes <= 0 {
+		return 1 / (1 - threshold)
+	}
+
+	const numVBuckets = 1024
+	multiplier := 1 / (1 - threshold)
+	multiplier += dataStaleBytesPerSec(dataset, workload) * (24 - compactionWindowHours(dataset)) * 3600 / liveBytes
+	multiplier += math.Min(compactionParallelism(dataset), numVBuckets) / numVBuckets / (1 - threshold)
+	return multiplier
+}
+
+// compactionBytesPerSec returns the data read and written by compactions keeping the fragmentation at its threshold,
+// compactions run inside the compaction window only (In bytes per second)
+func compactionBytesPerSec(staleBytesPerSec, threshold, windowHours float64) (read, written float64) {
+	// a compaction runs when the stale data reaches the threshold, reads the whole file and writes the live part
+	read = staleBytesPerSec / threshold
+	written = staleBytesPerSec * (1 - threshold) / threshold
+	return read * 24 / windowHours, written * 24 / windowHours
+}
+
+// calculateDataCompactionIO computes the disk IO of the Data compactions (In IOPS)
+func calculateDataCompactionIO(dataset models.Dataset, workload models.Workload) float64 {
+	threshold := fragmentationThreshold(dataset)
+	read, written := compactionBytesPerSec(dataStaleBytesPerSec(dataset, workload), threshold, compactionWindowHours(dataset))
+	return (read + written) / compactionIOSize
+}
+
+// CompactionThroughput returns the data the Data compactions read and write while they run (In MB per second)
+func CompactionThroughput(dataset models.Dataset, workload models.Workload) float64 {
+	threshold := fragmentationThreshold(dataset)
+	read, written := compactionBytesPerSec(dataStaleBytesPerSec(dataset, workload), threshold, compactionWindowHours(dataset))
+	return Round((read+written)/1024/1024, 2)
+}
+
+// CompactionCapacity returns the data the configured compactors can copy (In MB per second)
+func CompactionCapacity(dataset models.Dataset) float64 {
+	return compactionParallelism(dataset) * compactorThroughput / 1024 / 1024
+}
+
+// calculateIndexCompactionIO computes the disk IO of the Index compactions for the indexed mutations (In IOPS)
+func calculateIndexCompactionIO(dataset models.Dataset, workload models.Workload) float64 {
+	const indexEntryOverhead = 16
+	const numReplicas = 1
+
+	indexedMutationsPerSec := float64(workload.WritesPerSec+workload.DeletesPerSec) * float64(dataset.PercentIndexesOfDataset) / 100
+	staleBytesPerSec := indexedMutationsPerSec * (float64(dataset.AverageKeySize) + indexEntryOverhead) * (1 + numReplicas)
+	read, written := compactionBytesPerSec(staleBytesPerSec, indexFragmentationThreshold(dataset), 24)
+	return (read + written) / compactionIOSize
+}
@@ -5,31 +5,98 @@ import (
 	"workload-estimator-poc/models"
 )
 
+// DataOverhead holds the load other components add on top of the user's dataset and workload
+type DataOverhead struct {
+	ExtraReadsPerSec      float64
+	ExtraWritesPerSec     float64
+	XattrBytesPerDocument float64 // system xattrs stored with every document (In bytes)
+	ExtraDocuments        float64 // documents stored on behalf of other components
+	ExtraDocumentBytes    float64 // total size of the extra documents (In bytes)
+	DCPConsumers          float64 // connections streaming the mutations over DCP
+	XDCRReplications      float64 // replications of the bucket to other clusters, part of the DCP consumers
+}
+
+// Add returns the sum of both overheads
+func (o DataOverhead) Add(other DataOverhead) DataOverhead {
+	return DataOverhead{
+		ExtraReadsPerSec:      o.ExtraReadsPerSec + other.ExtraReadsPerSec,
+		ExtraWritesPerSec:     o.ExtraWritesPerSec + other.ExtraWritesPerSec,
+		XattrBytesPerDocument: o.XattrBytesPerDocument + other.XattrBytesPerDocument,
+		ExtraDocuments:        o.ExtraDocuments + other.ExtraDocuments,
+		ExtraDocumentBytes:    o.ExtraDocumentBytes + other.ExtraDocumentBytes,
+		DCPConsumers:          o.DCPConsumers + other.DCPConsumers,
+		XDCRReplications:      o.XDCRReplications + other.XDCRReplications,
+	}
+}
+
 // EstimateResourcesForData calculates resources required for the Data service.
-func EstimateResourcesForData(dataset models.Dataset, workload models.Workload) (ram, cpu, disk, diskIO float64) {
-	ram = calculateDataRAM(dataset, workload)
-	cpu = calculateDataCPU(dataset, workload)
-	disk = calculateDataDisk(dataset, workload)
+func EstimateResourcesForData(dataset models.Dataset, workload models.Workload, overhead DataOverhead) (ram, cpu, disk, diskIO float64) {
+	dataset, workload = applyDataOverhead(dataset, workload, overhead)
+
+	ram = calculateDataRAM(dataset, workload, overhead.DCPConsumers, overhead.XDCRReplications)
+	cpu = calculateDataCPU(dataset, workload, overhead.DCPConsumers, overhead.XDCRReplications)
+	disk = calculateDataDisk(dataset, workload, overhead.XDCRReplications)
 	diskIO = calculateDataDiskIO(dataset, workload)
 
 	return ram, cpu, disk, diskIO
 }
 
-// calculateDataRAM computes the RAM requirement for the Data service. (verified)
-func calculateDataRAM(dataset models.Dataset, workload models.Workload) float64 {
+// applyDataOverhead folds the overhead into copies of the dataset and workload the Data formulas work on
+func applyDataOverhead(dataset models.Dataset, workload models.Workload, overhead DataOverhead) (models.Dataset, models.Workload) {
+	// Step 1: Extra operations
+	workload.ReadPerSec += int64(math.Ceil(overhead.ExtraReadsPerSec))
+	workload.WritesPerSec += int64(math.Ceil(overhead.ExtraWritesPerSec))
+
+	// Step 2: Xattrs are stored with every document
+	xattrBytes := models.ByteSize(overhead.XattrBytesPerDocument)
+	dataset.AverageDocumentSize += xattrBytes
+	if histogram := DocumentSizeHistogram(dataset); len(histogram) > 0 {
+		adjusted := make([]models.DocumentSizeBucket, 0, len(histogram))
+		for _, bucket := range histogram {
+			bucket.Size += xattrBytes
+			adjusted = append(adjusted, bucket)
+		}
+		dataset.DocumentSizeHistogram = adjusted
+		dataset.DocumentSizePercentiles = nil
+	}
+	collections := make([]models.Collection, 0, len(dataset.Collections)+1)
+	for _, collection := range dataset.Collections {
+		if collection.AverageDocumentSize > 0 {
+			collection.AverageDocumentSize += xattrBytes
+		}
+		collections = append(collections, collection)
+	}
+
+	// Step 3: Extra documents are kept in a system collection of their own, the bucket-wide average includes them
+	if overhead.ExtraDocuments > 0 {
+		extraDocuments := int64(math.Ceil(overhead.ExtraDocuments))
+		extraDocumentSize := models.ByteSize(overhead.ExtraDocumentBytes/overhead.ExtraDocuments) + xattrBytes
+		collections = append(collections, models.Collection{
+			Name:                "_overhead",
+			Scope:               systemScope,
+			NoOfDocuments:       extraDocuments,
+			AverageDocumentSize: extraDocumentSize,
+		})
+		totalBytes := float64(dataset.NoOfDocuments)*float64(dataset.AverageDocumentSize) + float64(extraDocuments)*float64(extraDocumentSize)
+		dataset.NoOfDocuments += extraDocuments
+		dataset.AverageDocumentSize = models.ByteSize(totalBytes / float64(dataset.NoOfDocuments))
+	}
+	dataset.Collections = collections
+
+	return dataset, workload
+}
+
+// calculateDataRAM computes the RAM requirement for the Data service, document values are held in jemalloc size classes
+func calculateDataRAM(dataset models.Dataset, workload models.Workload, dcpConsumers, xdcrReplications float64) float64 {
 	// Constants
 	const ttlExpiration = 0
-	const avgKeySize = 0
-	const inboundXdcrStreams = 0
-	const outboundXdcrStreams = 0
-	const purgeFrequency = 3
-	const numReplicas = 1
+	avgKeySize := float64(dataset.AverageKeySize)
+	numReplicas := float64(BucketReplicas(dataset))
 	const bucketType = "Couchbase"
 	const bucketTypeCouchbase = 56
 	const bucketTypeEphemeral = 72
-	const compressionRatio = 0.3
 	const evictionPolicy = "Full"
-	const jemallocBinSize = 0.25
+	const jemallocBinSize = 0.25					// allocator fragmentation on top of the size class rounding
 	const highWaterMark = 0.85
 
 	// Step 1: Calculate Expiry Ops Per Second
@@ -41,9 +108,7 @@ func calculateDataRAM(dataset models.Dataset, workload models.Workload) float64
 	}
 
 	// Step 2: Calculate Tombstone Space (In bytes)
-	xdcrFactor := 60 * math.Max(1, float64(inboundXdcrStreams+outboundXdcrStreams))
-	tombstoneSpace := (float64(avgKeySize) + xdcrFactor) * float64(purgeFrequency) * float64(numReplicas+1) * 
-		(float64(workload.DeletesPerSec) + expiryOpsPerSec) * 60 * 60 * 24
+	tombstoneSpace := tombstoneBytes(dataset, workload, expiryOpsPerSec, xdcrReplications)
 
 	// Step 3: Calculate Active Metadata and Keyset Size (In bytes)
 	var bucketMetadataSize int
@@ -52,33 +117,37 @@ func calculateDataRAM(dataset models.Dataset, workload models.Workload) float64
 	} else {
 		bucketMetadataSize = bucketTypeEphemeral
 	}
-	totalActiveMetadataSize := float64(dataset.NoOfDocuments * int64(bucketMetadataSize))
-	totalActiveKeysetSize := float64(dataset.NoOfDocuments * avgKeySize)
-	totalActiveMetadataKeysetSize := totalActiveMetadataSize + totalActiveKeysetSize
-
-	// Step 4: Calculate Replica Metadata and Keyset Size
-	totalReplicaMetadataSize := float64(dataset.NoOfDocuments * int64(bucketMetadataSize) * numReplicas)
-	totalReplicaKeysetSize := float64(dataset.NoOfDocuments * avgKeySize * numReplicas)
-	totalReplicaMetadataKeysetSize := totalReplicaMetadataSize + totalReplicaKeysetSize
-
-	// Step 5: Calculate Active and Replica Dataset Sizes (In Bytes)
-	activeDatasetSize := float64(dataset.NoOfDocuments) * float64(dataset.AverageDocumentSize) * (1 - compressionRatio)
-	replicaDatasetSize := activeDatasetSize * float64(numReplicas)
-
-	// Step 6: Calculate Total Memory Required (In Bytes)
-	var totalMemoryRequired float64
-	if evictionPolicy == "Value" {
-		totalMemoryRequired = ((float64(dataset.ResidentRatio) / 100) * (activeDatasetSize + replicaDatasetSize)) + totalActiveMetadataKeysetSize + totalReplicaMetadataKeysetSize
-	} else { // Eviction policy = 'Full'
-		totalMemoryRequired = (float64(dataset.ResidentRatio) / 100) * ((activeDatasetSize + replicaDatasetSize) + totalActiveMetadataKeysetSize + totalReplicaMetadataKeysetSize)
-	}
 
-	// Step 7: Calculate Total + Jemalloc Bin Size + Tombstones
+	// Steps 3 to 7 run for every document size class and are aggregated
 	var totalWithJemallocAndTombstones float64
-	if evictionPolicy == "Value" {
-		totalWithJemallocAndTombstones = totalMemoryRequired + (totalMemoryRequired * jemallocBinSize)
-	} else { // Eviction policy = 'Full'
-		totalWithJemallocAndTombstones = totalMemoryRequired + (totalMemoryRequired * jemallocBinSize * (float64(dataset.ResidentRatio) / 100))
+	for _, class := range documentSizeClasses(dataset) {
+		totalActiveMetadataSize := class.documents * float64(bucketMetadataSize)
+		totalActiveKeysetSize := class.documents * avgKeySize
+		totalActiveMetadataKeysetSize := totalActiveMetadataSize + totalActiveKeysetSize
+
+		// Step 4: Calculate Replica Metadata and Keyset Size
+		totalReplicaMetadataSize := totalActiveMetadataSize * numReplicas
+		totalReplicaKeysetSize := totalActiveKeysetSize * numReplicas
+		totalReplicaMetadataKeysetSize := totalReplicaMetadataSize + totalReplicaKeysetSize
+
+		// Step 5: Calculate Active and Replica Dataset Sizes (In Bytes), every value takes the jemalloc size class it rounds up to
+		activeDatasetSize := class.documents * jemallocSizeClass(class.size*(1-class.compressionRatio))
+		replicaDatasetSize := activeDatasetSize * float64(numReplicas)
+
+		// Step 6: Calculate Total Memory Required (In Bytes)
+		var totalMemoryRequired float64
+		if evictionPolicy == "Value" {
+			totalMemoryRequired = ((float64(dataset.ResidentRatio) / 100) * (activeDatasetSize + replicaDatasetSize)) + totalActiveMetadataKeysetSize + totalReplicaMetadataKeysetSize
+		} else { // Eviction policy = 'Full'
+			totalMemoryRequired = (float64(dataset.ResidentRatio) / 100) * ((activeDatasetSize + replicaDatasetSize) + totalActiveMetadataKeysetSize + totalReplicaMetadataKeysetSize)
+		}
+
+		// Step 7: Calculate Total + Jemalloc Bin Size + Tombstones
+		if evictionPolicy == "Value" {
+			totalWithJemallocAndTombstones += totalMemoryRequired + (totalMemoryRequired * jemallocBinSize)
+		} else { // Eviction policy = 'Full'
+			totalWithJemallocAndTombstones += totalMemoryRequired + (totalMemoryRequired * jemallocBinSize * (float64(dataset.ResidentRatio) / 100))
+		}
 	}
 
 	// Add tombstone space if bucket type is Ephemeral
@@ -86,6 +155,12 @@ func calculateDataRAM(dataset models.Dataset, workload models.Workload) float64
 		totalWithJemallocAndTombstones += tombstoneSpace
 	}
 
+	// Add the per-vBucket memory of the scopes and collections
+	totalWithJemallocAndTombstones += calculateCollectionsRAM(dataset, numReplicas)
+
+	// Add the stream state, backfill buffers and checkpoints of the DCP consumers
+	totalWithJemallocAndTombstones += calculateDCPRAM(dataset, workload, dcpConsumers)
+
 	// Step 8: Calculate Total RAM Quota
 	totalRAMQuota := totalWithJemallocAndTombstones / highWaterMark
 
@@ -98,17 +173,51 @@ func calculateDataRAM(dataset models.Dataset, workload models.Workload) float64
 	return totalRAM
 }
 
-// calculateDataCPU computes the CPU requirement for the Data service. (verified)
-func calculateDataCPU(dataset models.Dataset, workload models.Workload) float64 {
+// DataCPUDemand returns the CPU the bucket's workload asks of the Data service, before the guardrail minimum and the
+// minimum cores of a bucket are applied and before it is split over the nodes (In cores)
+func DataCPUDemand(dataset models.Dataset, workload models.Workload, overhead DataOverhead) float64 {
+	dataset, workload = applyDataOverhead(dataset, workload, overhead)
+	return dataCPUDemand(dataset, workload, overhead.DCPConsumers, overhead.XDCRReplications)
+}
+
+// calculateDataCPU computes the CPU requirement for the Data service.
+func calculateDataCPU(dataset models.Dataset, workload models.Workload, dcpConsumers, xdcrReplications float64) float64 {
 	// Constants
-	const ttlExpiration = 0
-	const inboundXdcrStreams = 0
-	const outboundXdcrStreams = 0
-	const numberReplicas = 1
 	const storageEngine = "Couchstore"  // or "Magma"
 	const guardrails_cpu_per_bucket_min = 0.2
 	const minimum_number_of_cores_one_bucket = 4
 
+	// Step 1 - 4: CPU demand of the bucket, rounded to 1 decimal place
+	cpu := dataCPUDemand(dataset, workload, dcpConsumers, xdcrReplications)
+
+	// Step 5: Check for gaurdrails minimum
+	gaurdrails_minimum := 1 * guardrails_cpu_per_bucket_min				// here 1 corresponds to the number of buckets currently taking it as a single bucket
+
+	// Step 6: Get max cpu
+	cpu = max(gaurdrails_minimum, cpu)
+
+	// Step 7: Additions based on storage engine type
+	if storageEngine == "Couchstore"{
+		cpu += (minimum_number_of_cores_one_bucket - 1)
+	}
+
+	// Step 8: Upper bound cpu value
+	cpu = math.Ceil(cpu)
+
+	return cpu
+}
+
+// dataCPUDemand computes the CPU the workload of the bucket needs (In cores).
+// Every outbound XDCR replication takes a core, replications into the bucket arrive as writes of the workload.
+func dataCPUDemand(dataset models.Dataset, workload models.Workload, dcpConsumers, xdcrReplications float64) float64 {
+	// Constants
+	const ttlExpiration = 0
+	outboundXdcrStreams := xdcrReplications
+	numberReplicas := math.Max(float64(BucketReplicas(dataset)), 1)		// a bucket without replicas still pays for the active write path
+	const storageEngine = "Couchstore"  // or "Magma"
+	const readsPerCore = 40000.0							// reads served from memory per core per second
+	const backgroundFetchesPerCore = 10000.0	// reads fetched from disk per core per second
+
 	// Step 1: Compute Expiry Ops Per Second (Same as RAM calculation)
 	var expiryOpsPerSec float64
 	if ttlExpiration > 0 {
@@ -119,7 +228,17 @@ func calculateDataCPU(dataset models.Dataset, workload models.Workload) float64
 
 	// Step 2: Calculate CPU
 	// For storage engine of type "Couchstore"
-	cpu := float64(inboundXdcrStreams) + float64(outboundXdcrStreams) + (((float64(workload.WritesPerSec) + float64(workload.DeletesPerSec) + expiryOpsPerSec) * numberReplicas) / 10000)
+	cpu := outboundXdcrStreams + (((float64(workload.WritesPerSec) + float64(workload.DeletesPerSec) + expiryOpsPerSec) * numberReplicas) / 10000)
+	// synchronous writes wait for acknowledgements and flushes, the expiries stay asynchronous
+	durabilityLevel, _ := DurabilityLevel(dataset)
+	cpu += ((float64(workload.WritesPerSec) + float64(workload.DeletesPerSec)) * numberReplicas / 10000) * (durabilityCPUFactor(durabilityLevel) - 1)
+	// reads served from memory are cheaper than writes, reads missing the cache add a background fetch
+	backgroundFetchesPerSec := float64(workload.ReadPerSec) * dataCacheMissRatio(dataset, workload)
+	cpu += (float64(workload.ReadPerSec) / readsPerCore) + (backgroundFetchesPerSec / backgroundFetchesPerCore)
+	// every collection keeps checkpoints and DCP streams of its own
+	cpu += calculateCollectionsCPU(dataset)
+	// every other DCP consumer receives every mutation, the XDCR streams are part of their core
+	cpu += calculateDCPCPU(workload, dcpConsumers-xdcrReplications)
 	// For storage engine of type "Magma"
 	// Can be added (currently ignored for simplicity)
 
@@ -129,54 +248,34 @@ func calculateDataCPU(dataset models.Dataset, workload models.Workload) float64
 	}
 
 	// Step 4: Round to 1 decimal place
-	cpu = round(cpu, 1)
-
-	// Step 5: Check for gaurdrails minimum
-	gaurdrails_minimum := 1 * guardrails_cpu_per_bucket_min				// here 1 corresponds to the number of buckets currently taking it as a single bucket
-
-	// Step 6: Get max cpu
-	cpu = max(gaurdrails_minimum, cpu)
-
-	// Step 7: Additions based on storage engine type
-	if storageEngine == "Couchstore"{
-		cpu += (minimum_number_of_cores_one_bucket - 1)
-	}
-
-	// Step 8: Upper bound cpu value
-	cpu = math.Ceil(cpu)
+	cpu = Round(cpu, 1)
 
 	return cpu
 }
 
-// utility function
+// dataCacheMissRatio returns the share of reads that miss the cache and are fetched from disk
+func dataCacheMissRatio(dataset models.Dataset, workload models.Workload) float64 {
+	return 1 - cacheHitRatio(dataset, workload, float64(dataset.ResidentRatio)/100)
+}
+
+// Round rounds the value to the given number of decimals, shared by the services and the calculator
 // note : this function is a bit different from python's round function in sizing calculator as it rounds
 // to the next integer without any context of the digits being odd or even when ending with 5
 // but is recommended as it do not truncate certain values which might result in a lesser value than expected
-func round(val float64, precision int) float64 {
+func Round(val float64, precision int) float64 {
 	factor := math.Pow(10, float64(precision))
 	return math.Round(val*factor) / factor
 }
 
-// calculateDataDisk computes the Disk Space requirement for the Data service. (verified)
-func calculateDataDisk(dataset models.Dataset, workload models.Workload) float64 {
+// calculateDataDisk computes the Disk Space requirement for the Data service, fragmentation follows the bucket's compaction settings
+func calculateDataDisk(dataset models.Dataset, workload models.Workload, xdcrReplications float64) float64 {
 	// Constants
 	const ttlExpiration = 0					// in days
-	const avgKeySize = 0						// in bytes
-	const inboundXdcrStreams = 0
-	const outboundXdcrStreams = 0
-	const purgeFrequency = 3
-	const numReplicas = 1
+	avgKeySize := float64(dataset.AverageKeySize)						// in bytes
+	numReplicas := float64(BucketReplicas(dataset))
 	const bucketType = "Couchbase"
 	const bucketTypeCouchbase = 56
 	const bucketTypeEphemeral = 72
-	const compressionRatio = 0.3
-	const storageEngine = "Couchstore" // or "Magma"
-	var appendOnlyMultiplier = 3
-
-	// Step -1: Set appendOnlyMultiplier
-	if storageEngine == "Magma" {
-		appendOnlyMultiplier = 2
-	}
 
 	// Step 0: Choose process based on type of storage engine
 	// If storage engine is of type "Magma" then use calculate_magma_disk_space function in sizing calculator
@@ -191,48 +290,44 @@ func calculateDataDisk(dataset models.Dataset, workload models.Workload) float64
 	}
 
 	// Step 2: Compute Tombstone Space (In Bytes)
-	// count number of streams configured
-	var count = 0
-	if inboundXdcrStreams > 0 {
-		count++
-	}
-	if outboundXdcrStreams > 0 {
-		count++
-	}	
-	// calculate tombstone space
-	tombstoneSpace := math.Round((float64(avgKeySize) + (60 * math.Max(1, float64(count)))) *
-		float64(purgeFrequency) * float64(numReplicas+1) * (float64(workload.DeletesPerSec) + expiryOpsPerSec) * 60 * 60 * 24)
-
-	// Step 3: Compute Metadata & Keyset Sizes (In Bytes)
-	// active data
-	activeMetadataSize := float64(dataset.NoOfDocuments) * bucketTypeCouchbase
-	if bucketType == "Ephemeral" {
-		activeMetadataSize = float64(dataset.NoOfDocuments) * bucketTypeEphemeral
-	}
-	activeKeysetSize := float64(dataset.NoOfDocuments) * float64(avgKeySize)
-	totalActiveMetadataKeysetSize := activeMetadataSize + activeKeysetSize
-	
-	// replica data
-	replicaMetadataSize := (activeMetadataSize * numReplicas)
-	replicaKeysetSize := float64(dataset.NoOfDocuments) * float64(avgKeySize) * float64(numReplicas)
-	totalReplicaMetadataKeysetSize := replicaMetadataSize + replicaKeysetSize
-
-	totalMetadataKeysetSize := totalActiveMetadataKeysetSize + totalReplicaMetadataKeysetSize
-
-	// Step 4: Compute Dataset Sizes (In Bytes)
-	activeDatasetSize := float64(dataset.NoOfDocuments) * float64(dataset.AverageDocumentSize)
-	replicaDatasetSize := activeDatasetSize * float64(numReplicas)
-
-	// Step 5: Compute Size on Disk
-	var sizeOnDisk float64
-	if bucketType == "Ephemeral" {
-		sizeOnDisk = 0
-	} else {
-		result := activeDatasetSize + replicaDatasetSize
-		if compressionRatio != 0 {
-			result *= (1 - compressionRatio)
+	tombstoneSpace := math.Round(tombstoneBytes(dataset, workload, expiryOpsPerSec, xdcrReplications))
+
+	// Steps 3 to 5 run for every document size class and are aggregated
+	var sizeOnDisk, liveOnDisk float64
+	for _, class := range documentSizeClasses(dataset) {
+		// Step 3: Compute Metadata & Keyset Sizes (In Bytes)
+		// active data
+		activeMetadataSize := class.documents * bucketTypeCouchbase
+		if bucketType == "Ephemeral" {
+			activeMetadataSize = class.documents * bucketTypeEphemeral
 		}
-		sizeOnDisk = ((result + totalMetadataKeysetSize) * float64(appendOnlyMultiplier)) + tombstoneSpace
+		activeKeysetSize := class.documents * float64(avgKeySize)
+		totalActiveMetadataKeysetSize := activeMetadataSize + activeKeysetSize
+
+		// replica data
+		replicaMetadataSize := (activeMetadataSize * numReplicas)
+		replicaKeysetSize := class.documents * float64(avgKeySize) * float64(numReplicas)
+		totalReplicaMetadataKeysetSize := replicaMetadataSize + replicaKeysetSize
+
+		totalMetadataKeysetSize := totalActiveMetadataKeysetSize + totalReplicaMetadataKeysetSize
+
+		// Step 4: Compute Dataset Sizes (In Bytes)
+		activeDatasetSize := class.documents * class.size
+		replicaDatasetSize := activeDatasetSize * float64(numReplicas)
+
+		// Step 5: Compute Size on Disk
+		if bucketType != "Ephemeral" {
+			result := activeDatasetSize + replicaDatasetSize
+			if class.compressionRatio != 0 {
+				result *= (1 - class.compressionRatio)
+			}
+			liveOnDisk += result + totalMetadataKeysetSize
+		}
+	}
+	// the live data grows by the steady-state fragmentation the compaction settings allow
+	sizeOnDisk += liveOnDisk * dataDiskMultiplier(dataset, workload, liveOnDisk)
+	if bucketType != "Ephemeral" {
+		sizeOnDisk += tombstoneSpace
 	}
 
 	// Step 6: Convert size into GB and round off to ceil value
@@ -241,11 +336,11 @@ func calculateDataDisk(dataset models.Dataset, workload models.Workload) float64
 	return sizeOnDisk
 }
 
-// calculateDataDiskIO computes the Disk I/O requirement for the Data service. (verified)
+// calculateDataDiskIO computes the Disk I/O requirement for the Data service, compactions and background fetches included
 func calculateDataDiskIO( dataset models.Dataset, workload models.Workload) float64 {
 	// Constants
 	const ttlExpiration = 0   // in days
-	const numReplicas = 1
+	numReplicas := BucketReplicas(dataset)
 	const bucketType = "Couchbase"
 
 	// Step 0: If storage engine is "Magma" then use different process as per sizing calculator need to use calculate_magma_disk_io function
@@ -265,6 +360,13 @@ func calculateDataDiskIO( dataset models.Dataset, workload models.Workload) floa
 		diskIO = 0
 	} else {
 		diskIO = (float64(workload.WritesPerSec) + float64(workload.DeletesPerSec) + expiryOpsPerSec) * float64(numReplicas+1)
+		// Step 3: Synchronous writes persisting before they are acknowledged are flushed and fsynced one by one instead of in batches
+		durabilityLevel, _ := DurabilityLevel(dataset)
+		diskIO += (float64(workload.WritesPerSec) + float64(workload.DeletesPerSec)) * durabilityPersistingCopies(durabilityLevel, numReplicas)
+		// Step 4: Add the reads and writes of the compactions
+		diskIO += calculateDataCompactionIO(dataset, workload)
+		// Step 5: Add background fetches of the reads missing the cache (replicas serve no reads)
+		diskIO += float64(workload.ReadPerSec) * dataCacheMissRatio(dataset, workload)
 	}
 
 	return diskIO
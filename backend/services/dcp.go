@@ -0,0 +1,44 @@
+package services
+
+import (
+	"workload-estimator-poc/models"
+)
+
+// DCPDataOverhead returns the DCP consumers the request's services and replications open against the Data service
+func DCPDataOverhead(consumers models.DCPConsumers) DataOverhead {
+	total := consumers.Indexes + consumers.SearchIndexes + consumers.EventingFunctions + consumers.AnalyticsLinks + consumers.XDCRReplications
+	return DataOverhead{DCPConsumers: float64(max(total, 0)), XDCRReplications: float64(max(consumers.XDCRReplications, 0))}
+}
+
+// calculateDCPRAM computes the stream state, backfill buffers and checkpoints kept for the DCP consumers (In bytes)
+func calculateDCPRAM(dataset models.Dataset, workload models.Workload, consumers float64) float64 {
+	// Constants
+	const numVBuckets = 1024
+	const streamStatePerVBucket = 2 * 1024			// state of an active vBucket stream (In bytes)
+	const backfillBuffer = 20 * 1024 * 1024			// buffer of a connection backfilling from disk (In bytes)
+	const cursorLag = 2.0												// time a consumer's cursor trails the latest checkpoint (In seconds)
+	const bucketTypeCouchbase = 56
+
+	if consumers == 0 {
+		return 0
+	}
+
+	// Step 1: Fixed memory of every connection
+	perConsumer := float64(numVBuckets*streamStatePerVBucket + backfillBuffer)
+
+	// Step 2: Checkpoints held back by a lagging cursor
+	mutationsPerSec := float64(workload.WritesPerSec + workload.DeletesPerSec)
+	perConsumer += mutationsPerSec * cursorLag * (float64(dataset.AverageDocumentSize) + float64(dataset.AverageKeySize) + bucketTypeCouchbase)
+
+	return perConsumer * consumers
+}
+
+// calculateDCPCPU computes the CPU the Data service spends streaming mutations to the DCP consumers (In cores)
+func calculateDCPCPU(workload models.Workload, consumers float64) float64 {
+	// Constants
+	const itemsPerCore = 50000.0				// mutations a single core streams per second
+	const perConsumerCores = 0.02				// connection and vBucket stream management
+
+	mutationsPerSec := float64(workload.WritesPerSec + workload.DeletesPerSec)
+	return consumers * (mutationsPerSec/itemsPerCore + perConsumerCores)
+}
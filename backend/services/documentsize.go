@@ -0,0 +1,143 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"workload-estimator-poc/models"
+)
+
+// defaultCompressionRatio is the share of the document size saved by Snappy compression when no empirical ratio is known
+const defaultCompressionRatio = 0.3
+
+// documentClass is a group of documents sharing the same size, the Data formulas run once per class
+type documentClass struct {
+	documents        float64
+	size             float64 // in bytes
+	compressionRatio float64
+}
+
+// documentSizeClasses splits the dataset into document size classes.
+// A plain average document size without collection overrides is the degenerate case of a single class.
+// Data RAM rounds every class to its own jemalloc size class, so a skewed distribution differs from its mean.
+func documentSizeClasses(dataset models.Dataset) []documentClass {
+	datasetCompressionRatio := defaultCompressionRatio
+	if dataset.CompressionRatio != nil {
+		datasetCompressionRatio = *dataset.CompressionRatio
+	}
+
+	// Collections with their own document count and size form classes of their own. Without a histogram the average
+	// document size covers the whole bucket, the remaining documents take the size left over by the collections.
+	// A histogram describes the remaining documents only.
+	var classes []documentClass
+	remainingDocuments := float64(dataset.NoOfDocuments)
+	remainingBytes := float64(dataset.NoOfDocuments) * float64(dataset.AverageDocumentSize)
+	for _, collection := range dataset.Collections {
+		if collection.NoOfDocuments <= 0 || collection.AverageDocumentSize <= 0 {
+			continue
+		}
+		classes = append(classes, documentClass{
+			documents:        float64(collection.NoOfDocuments),
+			size:             float64(collection.AverageDocumentSize),
+			compressionRatio: datasetCompressionRatio,
+		})
+		remainingDocuments -= float64(collection.NoOfDocuments)
+		remainingBytes -= float64(collection.NoOfDocuments) * float64(collection.AverageDocumentSize)
+	}
+	remainingDocuments = math.Max(remainingDocuments, 0)
+
+	histogram := DocumentSizeHistogram(dataset)
+	if len(histogram) == 0 {
+		remainingSize := float64(dataset.AverageDocumentSize)
+		if len(classes) > 0 && remainingDocuments > 0 {
+			remainingSize = math.Max(remainingBytes/remainingDocuments, 0)
+		}
+		return append(classes, documentClass{
+			documents:        remainingDocuments,
+			size:             remainingSize,
+			compressionRatio: datasetCompressionRatio,
+		})
+	}
+
+	var totalPercent float64
+	for _, bucket := range histogram {
+		totalPercent += bucket.Percent
+	}
+
+	for _, bucket := range histogram {
+		compressionRatio := datasetCompressionRatio
+		if bucket.CompressionRatio != nil {
+			compressionRatio = *bucket.CompressionRatio
+		}
+		classes = append(classes, documentClass{
+			documents:        remainingDocuments * bucket.Percent / totalPercent,
+			size:             float64(bucket.Size),
+			compressionRatio: compressionRatio,
+		})
+	}
+	return classes
+}
+
+// jemallocSizeClass returns the size of the jemalloc allocation holding a value of the given size (In bytes).
+// Classes are spaced 16 bytes apart up to 128 bytes, above that every doubling is split into 4 classes.
+func jemallocSizeClass(size float64) float64 {
+	const minClass = 8
+	const smallClassSpacing = 16
+	const smallClassLimit = 128
+
+	if size <= minClass {
+		return minClass
+	}
+	if size <= smallClassLimit {
+		return math.Ceil(size/smallClassSpacing) * smallClassSpacing
+	}
+	group := math.Pow(2, math.Floor(math.Log2(math.Ceil(size)-1)))
+	spacing := group / 4
+	return math.Ceil(size/spacing) * spacing
+}
+
+// DocumentSizeHistogram returns the document size histogram of the dataset, percentiles are turned into buckets.
+// Returns nil when the dataset only has an average document size.
+func DocumentSizeHistogram(dataset models.Dataset) []models.DocumentSizeBucket {
+	var histogram []models.DocumentSizeBucket
+	for _, bucket := range dataset.DocumentSizeHistogram {
+		if bucket.Percent > 0 {
+			histogram = append(histogram, bucket)
+		}
+	}
+	if len(histogram) > 0 || len(dataset.DocumentSizePercentiles) == 0 {
+		return histogram
+	}
+
+	percentiles := append([]models.DocumentSizePercentile(nil), dataset.DocumentSizePercentiles...)
+	sort.Slice(percentiles, func(i, j int) bool { return percentiles[i].Percentile < percentiles[j].Percentile })
+
+	// documents between two percentiles take the midpoint of both sizes,
+	// documents below the first percentile take its size and those above the last one take the last size
+	previousPercentile, previousSize := 0.0, percentiles[0].Size
+	for _, percentile := range percentiles {
+		if percentile.Percentile > previousPercentile {
+			histogram = append(histogram, models.DocumentSizeBucket{
+				Size:    (previousSize + percentile.Size) / 2,
+				Percent: percentile.Percentile - previousPercentile,
+			})
+		}
+		previousPercentile, previousSize = percentile.Percentile, percentile.Size
+	}
+	if previousPercentile < 100 {
+		histogram = append(histogram, models.DocumentSizeBucket{Size: previousSize, Percent: 100 - previousPercentile})
+	}
+	return histogram
+}
+
+// AverageDocumentSize returns the mean document size over the histogram and the collections of the dataset
+func AverageDocumentSize(dataset models.Dataset) float64 {
+	var totalDocuments, totalBytes float64
+	for _, class := range documentSizeClasses(dataset) {
+		totalDocuments += class.documents
+		totalBytes += class.documents * class.size
+	}
+	if totalDocuments == 0 {
+		return float64(dataset.AverageDocumentSize)
+	}
+	return totalBytes / totalDocuments
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"workload-estimator-poc/models"
+)
+
+func TestJemallocSizeClass(t *testing.T) {
+	tests := []struct {
+		size float64
+		want float64
+	}{
+		{size: 0, want: 8},
+		{size: 5, want: 8},
+		{size: 8, want: 8},
+		{size: 9, want: 16},
+		{size: 17, want: 32},
+		{size: 128, want: 128},
+		{size: 129, want: 160},
+		{size: 200, want: 224},
+		{size: 256, want: 256},
+		{size: 257, want: 320},
+		{size: 1000, want: 1024},
+		{size: 1025, want: 1280},
+		{size: 4096, want: 4096},
+		{size: 4097, want: 5120},
+	}
+	for _, test := range tests {
+		if got := jemallocSizeClass(test.size); got != test.want {
+			t.Errorf("jemallocSizeClass(%v) = %v, want %v", test.size, got, test.want)
+		}
+	}
+}
+
+func TestDocumentSizeHistogram(t *testing.T) {
+	tests := []struct {
+		name    string
+		dataset models.Dataset
+		want    []models.DocumentSizeBucket
+	}{
+		{
+			name:    "average only",
+			dataset: models.Dataset{AverageDocumentSize: 1024},
+		},
+		{
+			name: "empty buckets are dropped",
+			dataset: models.Dataset{DocumentSizeHistogram: []models.DocumentSizeBucket{
+				{Size: 512, Percent: 60}, {Size: 4096, Percent: 0}, {Size: 8192, Percent: 40},
+			}},
+			want: []models.DocumentSizeBucket{{Size: 512, Percent: 60}, {Size: 8192, Percent: 40}},
+		},
+		{
+			name: "histogram wins over percentiles",
+			dataset: models.Dataset{
+				DocumentSizeHistogram:   []models.DocumentSizeBucket{{Size: 512, Percent: 100}},
+				DocumentSizePercentiles: []models.DocumentSizePercentile{{Percentile: 50, Size: 1000}},
+			},
+			want: []models.DocumentSizeBucket{{Size: 512, Percent: 100}},
+		},
+		{
+			name: "percentiles become buckets between them, in any order",
+			dataset: models.Dataset{DocumentSizePercentiles: []models.DocumentSizePercentile{
+				{Percentile: 90, Size: 4000}, {Percentile: 50, Size: 1000}, {Percentile: 99, Size: 10000},
+			}},
+			want: []models.DocumentSizeBucket{
+				{Size: 1000, Percent: 50}, {Size: 2500, Percent: 40}, {Size: 7000, Percent: 9}, {Size: 10000, Percent: 1},
+			},
+		},
+		{
+			name:    "a 100th percentile leaves no bucket above it",
+			dataset: models.Dataset{DocumentSizePercentiles: []models.DocumentSizePercentile{{Percentile: 50, Size: 100}, {Percentile: 100, Size: 300}}},
+			want:    []models.DocumentSizeBucket{{Size: 100, Percent: 50}, {Size: 200, Percent: 50}},
+		},
+	}
+	for _, test := range tests {
+		if got := DocumentSizeHistogram(test.dataset); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: DocumentSizeHistogram() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
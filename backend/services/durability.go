@@ -0,0 +1,102 @@
+package services
+
+import (
+	"strings"
+	"workload-estimator-poc/models"
+)
+
+// Durability levels of synchronous writes
+const (
+	DurabilityNone                     = "none"
+	DurabilityMajority                 = "majority"
+	DurabilityMajorityAndPersistActive = "majorityAndPersistActive"
+	DurabilityPersistToMajority        = "persistToMajority"
+)
+
+// MaxDurableReplicas is the highest replica count synchronous writes support
+const MaxDurableReplicas = 2
+
+// durabilityLevels maps the accepted level names (lower case) to the durability levels
+var durabilityLevels = map[string]string{
+	"":                         DurabilityNone,
+	"none":                     DurabilityNone,
+	"majority":                 DurabilityMajority,
+	"majorityandpersistactive": DurabilityMajorityAndPersistActive,
+	"persisttomajority":        DurabilityPersistToMajority,
+}
+
+// BucketReplicas returns the replica count of the bucket, 1 when unset
+func BucketReplicas(dataset models.Dataset) int64 {
+	if dataset.Bucket.NumReplicas == nil {
+		return 1
+	}
+	return max(*dataset.Bucket.NumReplicas, 0)
+}
+
+// DurabilityLevel returns the durability level of the bucket, ok is false for an unknown level which is treated as none
+func DurabilityLevel(dataset models.Dataset) (level string, ok bool) {
+	level, ok = durabilityLevels[strings.ToLower(strings.TrimSpace(dataset.Bucket.DurabilityLevel))]
+	if !ok {
+		return DurabilityNone, false
+	}
+	return level, true
+}
+
+// MajorityCopies returns the copies (active included) that must acknowledge a synchronous write
+func MajorityCopies(replicas int64) int64 {
+	return (replicas+1)/2 + 1
+}
+
+// durabilityCPUFactor returns the write path CPU of a synchronous write relative to an asynchronous one,
+// covering the durability monitor, the replica acknowledgements and the flushes the write waits for
+func durabilityCPUFactor(level string) float64 {
+	switch level {
+	case DurabilityMajority:
+		return 1.15
+	case DurabilityMajorityAndPersistActive:
+		return 1.25
+	case DurabilityPersistToMajority:
+		return 1.4
+	}
+	return 1
+}
+
+// durabilityPersistingCopies returns the copies that flush and fsync every synchronous write instead of batching it
+func durabilityPersistingCopies(level string, replicas int64) float64 {
+	switch level {
+	case DurabilityMajorityAndPersistActive:
+		return 1
+	case DurabilityPersistToMajority:
+		return float64(min(MajorityCopies(replicas), replicas+1))
+	}
+	return 0
+}
+
+// EstimateWriteLatency estimates the latency of a single write on the given disk type (In milliseconds)
+func EstimateWriteLatency(dataset models.Dataset, diskType string) float64 {
+	// Constants
+	const memoryWriteLatency = 0.2							// write to the active vBucket's hash table (In ms)
+	const replicationRoundTrip = 0.5						// replication to a replica node and its acknowledgement (In ms)
+
+	// Step 1: Flush and fsync latency of the disk type (In ms)
+	fsyncLatency := 2.0
+	if diskType == "io2" {
+		fsyncLatency = 0.8
+	}
+
+	// Step 2: Latency the write waits for
+	level, _ := DurabilityLevel(dataset)
+	latency := memoryWriteLatency
+	switch level {
+	case DurabilityMajority:
+		latency += replicationRoundTrip
+	case DurabilityMajorityAndPersistActive:
+		// the active persists while the replicas acknowledge
+		latency += max(replicationRoundTrip, fsyncLatency)
+	case DurabilityPersistToMajority:
+		// the replicas persist before they acknowledge
+		latency += replicationRoundTrip + fsyncLatency
+	}
+
+	return Round(latency, 2)
+}
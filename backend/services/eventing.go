@@ -52,25 +52,25 @@ func CalculateEventingCPU(dataset models.Dataset, workload models.Workload) floa
 	var mutationRatePerSec float64 = float64(workload.WritesPerSec) + float64(workload.DeletesPerSec) + expiryOpsPerSec
 
 	// Step 3: Calculate CPU required for source bucket mutation
-	var sourceBucketMutationRateCpuRequired float64 = round((mutationRatePerSec * numberOfHandlers) / sourceBucketMutationRateFactor, 3)
+	var sourceBucketMutationRateCpuRequired float64 = Round((mutationRatePerSec * numberOfHandlers) / sourceBucketMutationRateFactor, 3)
 
 	// Step 4: Calculate CPU required for number of handlers
-	var numberOfHandlersCpuRequired float64 = round(numberOfHandlers / handlerCountPerCoreFactor, 3)
+	var numberOfHandlersCpuRequired float64 = Round(numberOfHandlers / handlerCountPerCoreFactor, 3)
 
 	// Step 5: Calculate Bucket Operations CPU required
-	var bucketOpsCpuRequired float64 = round(((numberOfReadOpsPerExecution + numberOfWriteOpsPerExecution + numberOfDeleteOpsPerExecution) * (mutationRatePerSec * percentageDocsInFunction)) / bucketOpsPerCoreFactor, 3)
+	var bucketOpsCpuRequired float64 = Round(((numberOfReadOpsPerExecution + numberOfWriteOpsPerExecution + numberOfDeleteOpsPerExecution) * (mutationRatePerSec * percentageDocsInFunction)) / bucketOpsPerCoreFactor, 3)
 
 	// Step 6: Calculate Timer Operations CPU required
-	var timerOpsCpuRequired float64 = round((mutationRatePerSec * percentageDocsInFunction) / timersPerCoreFactor * numberOfTimersCreatedPerExecution, 3)
+	var timerOpsCpuRequired float64 = Round((mutationRatePerSec * percentageDocsInFunction) / timersPerCoreFactor * numberOfTimersCreatedPerExecution, 3)
 
 	// Step 7: Calculate N1QL Operations CPU required
-	var n1qlOpsCpuRequired float64 = round((mutationRatePerSec * percentageDocsInFunction) / n1qlPerCoreFactor * numberOfN1qlQueriesPerExecution, 3)
+	var n1qlOpsCpuRequired float64 = Round((mutationRatePerSec * percentageDocsInFunction) / n1qlPerCoreFactor * numberOfN1qlQueriesPerExecution, 3)
 
 	// Step 8: Calculate Log Operations CPU required
-	var logOpsCpuRequired float64 = round((mutationRatePerSec * percentageDocsInFunction) / logPerCoreFactor * numberOfLogStatementsPerExecution, 3)
+	var logOpsCpuRequired float64 = Round((mutationRatePerSec * percentageDocsInFunction) / logPerCoreFactor * numberOfLogStatementsPerExecution, 3)
 
 	// Step 9: Calculate CURL Operations CPU required
-	var curlOpsCpuRequired float64 = round((mutationRatePerSec * percentageDocsInFunction) / curlPerCoreFactor * numberOfCurlStatementsPerExecution, 3)
+	var curlOpsCpuRequired float64 = Round((mutationRatePerSec * percentageDocsInFunction) / curlPerCoreFactor * numberOfCurlStatementsPerExecution, 3)
 
 	// Step 10: Calculate total CPU required
 	var totalCpuRequired = sourceBucketMutationRateCpuRequired + numberOfHandlersCpuRequired + bucketOpsCpuRequired + timerOpsCpuRequired + n1qlOpsCpuRequired + logOpsCpuRequired + curlOpsCpuRequired
@@ -10,14 +10,14 @@ func EstimateResourcesForIndex(dataset models.Dataset, workload models.Workload)
 	cpu = calculateIndexCPU()
 	ram = calculateIndexRAM(dataset, cpu)
 	disk = calculateIndexDisk(dataset)
-	diskIO = calculateIndexDiskIO()
+	diskIO = calculateIndexDiskIO(dataset, workload)
 	return ram, cpu, disk, diskIO
 }
 
-// calculateIndexRAM computes the RAM required for indexing. (verified)
+// calculateIndexRAM computes the RAM required for indexing.
 func calculateIndexRAM(dataset models.Dataset, cpuAvailable float64) float64 {
 	// Constants
-	const avgKeySize = 0
+	avgKeySize := float64(dataset.AverageKeySize)
 	const mutationIngestRate = 0                           // comes under the advanced section of sizing calculator
 	const primaryIndex = false                             // under advanced section - is false by default as not suitable for production
 	const arrayLength = 0                                  // under advanced section - is by default kept to 0
@@ -82,7 +82,7 @@ func calculateIndexRAM(dataset models.Dataset, cpuAvailable float64) float64 {
 	}
 
 	// Step 6: Total memory (In bytes)
-	totalMemory := versionsGeneratedMvcc + plasmaMemUsageSecIdx + plasmaMemUsagePrimIdx + plasmaMemUsageArrIdx + plasmaWriteBuffer + memOverheadMutationBuffer + encodeBufferOverhead + maxMutationQueueSizeOverhead + fixCostIndexerCommBuffers + tempAllocationProtobuf
+	totalMemory := versionsGeneratedMvcc + plasmaMemUsageSecIdx + plasmaMemUsagePrimIdx + plasmaMemUsageArrIdx + plasmaWriteBuffer + memOverheadMutationBuffer + encodeBufferOverhead + maxMutationQueueSizeOverhead + fixCostIndexerCommBuffers + tempAllocationProtobuf + calculateCollectionsIndexRAM(dataset)
 
 	// Step 7: Overhead for Golang memory management (In GB)
 	expMaxMemUsageGB := (totalMemory * 1.05) / (1024 * 1024 * 1024)
@@ -140,10 +140,10 @@ func calculateIndexCPU() float64 {
 	return recommendedCores
 }
 
-// calculateIndexDisk computes the disk space required for indexing. (verified)
+// calculateIndexDisk computes the disk space required for indexing.
 func calculateIndexDisk(dataset models.Dataset) float64 {
 	// Constants
-	const avgKeySize = 0
+	avgKeySize := float64(dataset.AverageKeySize)
 	const primaryIndex = false
 	const arrayLength = 0
 	const totalSecondaryBytes = 0
@@ -177,7 +177,7 @@ func calculateIndexDisk(dataset models.Dataset) float64 {
 	var diskSizeAfterSnappy float64 = indexDiskUsage * 0.8
 
 	// Step 3: Fragmentation (In Bytes)
-	var fragmentation float64 = indexDiskUsage * 0.3
+	var fragmentation float64 = indexDiskUsage * indexFragmentationThreshold(dataset)
 	
 	// Step 4: Expected Max Disk Usage (In GB)
 	var expMaxDiskUsage float64 = (diskSizeAfterSnappy + fragmentation) / 1024 / 1024 / 1024
@@ -194,7 +194,8 @@ func calculateIndexDisk(dataset models.Dataset) float64 {
 	return recommendedDiskQuota
 }
 
-// calculateIndexDiskIO computes the disk I/O requirement for the Index service. (verified)
-func calculateIndexDiskIO() float64 {
-	return 0
+// calculateIndexDiskIO computes the disk I/O requirement for the Index service.
+func calculateIndexDiskIO(dataset models.Dataset, workload models.Workload) float64 {
+	// compactions rewriting the index files are the only disk IO accounted for
+	return math.Ceil(calculateIndexCompactionIO(dataset, workload))
 }
@@ -39,13 +39,13 @@ func CalculateQueryCPU(workload models.Workload) float64 {
 	const COMPLEX_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_FALSE = 200.0 / 24.0
 
 	// Step 1: Simple Query CPU Calculation
-	simpleQueryCPU := round((simpleQueryThroughputPerSecStaleOk / SIMPLE_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_OK) + (simpleQueryThroughputPerSecStaleFalse / SIMPLE_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_FALSE), 2)
+	simpleQueryCPU := Round((simpleQueryThroughputPerSecStaleOk / SIMPLE_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_OK) + (simpleQueryThroughputPerSecStaleFalse / SIMPLE_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_FALSE), 2)
 
 	// Step 2: Medium Query CPU Calculation
-	mediumQueryCPU := round((mediumQueryThroughputPerSecStaleOk / MEDIUM_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_OK)	+ (float64(mediumQueryThroughputPerSecStaleFalse) / MEDIUM_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_FALSE), 2)
+	mediumQueryCPU := Round((mediumQueryThroughputPerSecStaleOk / MEDIUM_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_OK)	+ (float64(mediumQueryThroughputPerSecStaleFalse) / MEDIUM_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_FALSE), 2)
 
 	// Step 3: Complex Query CPU Calculation
-	complexQueryCPU := round((complexQueryThroughputPerSecStaleOk / COMPLEX_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_OK) + (complexQueryThroughputPerSecStaleFalse / COMPLEX_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_FALSE), 2)
+	complexQueryCPU := Round((complexQueryThroughputPerSecStaleOk / COMPLEX_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_OK) + (complexQueryThroughputPerSecStaleFalse / COMPLEX_QUERY_QUERIES_PER_SEC_PER_CORE_STALE_FALSE), 2)
 
 	// Step 4: Calculate Total CPU
 	totalCPU := math.Ceil(simpleQueryCPU + mediumQueryCPU + complexQueryCPU)
@@ -25,7 +25,7 @@ func CalculateSearchRAM() float64 {
 	const scansPerSecond = 0						// Currently taking as constant but required from user
 
 	// RAM Calculation (In GB)
-	ram := math.Ceil(round(((maxSize + maxFrom + searchResultsSize) * float64(documentMatchStructure)) / ((1024 * 1024 * 1024)) * scansPerSecond, 2))
+	ram := math.Ceil(Round(((maxSize + maxFrom + searchResultsSize) * float64(documentMatchStructure)) / ((1024 * 1024 * 1024)) * scansPerSecond, 2))
 	return ram
 }
 
@@ -48,8 +48,8 @@ func CalculateSearchDisk(dataset models.Dataset, workload models.Workload) float
 	const includeInAllFields = false
 	const includeTermVectors = false
 	const docValues = false
-	const avgKeySize = 0
-	const avgFieldLength = 0
+	avgKeySize := float64(dataset.AverageKeySize)
+	avgFieldLength := float64(dataset.AverageFieldLength)
 	const fieldLength = 1.21
 	const numReplicas = 0
 
@@ -78,9 +78,9 @@ func CalculateSearchDisk(dataset models.Dataset, workload models.Workload) float
 	// Step 3: Index Size Calculation (In MB)
 	var indexSize float64 = 0.0
 	if countIfAll == 0 && !index{
-		indexSize = round(((float64(dataset.NoOfDocuments) * avgKeySize) + (numberOfDocuments * avgFieldLength * fieldLength * 1.3)) / (1024 * 1024), 0)
+		indexSize = Round(((float64(dataset.NoOfDocuments) * avgKeySize) + (numberOfDocuments * avgFieldLength * fieldLength * 1.3)) / (1024 * 1024), 0)
 	} else {
-		indexSize = round(((float64(dataset.NoOfDocuments) * avgKeySize) + (numberOfDocuments * avgFieldLength * fieldLength * float64(countIfAll) * 1.5 * fieldLength)) / (1024 * 1024), 0)
+		indexSize = Round(((float64(dataset.NoOfDocuments) * avgKeySize) + (numberOfDocuments * avgFieldLength * fieldLength * float64(countIfAll) * 1.5 * fieldLength)) / (1024 * 1024), 0)
 	}
 
 	// Step 4: Disk Space Required
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"math"
+	"workload-estimator-poc/models"
+)
+
+// EstimateResourcesForSyncGateway calculates resources required for the Sync Gateway / App Services nodes.
+func EstimateResourcesForSyncGateway(dataset models.Dataset, workload models.Workload, syncGateway models.SyncGatewayWorkload) (ram, cpu, disk, diskIO float64) {
+	ram = CalculateSyncGatewayRAM(dataset, syncGateway)
+	cpu = CalculateSyncGatewayCPU(workload, syncGateway)
+	// Sync Gateway keeps no persistent data of its own
+	disk = 0
+	diskIO = 0
+
+	return ram, cpu, disk, diskIO
+}
+
+// CalculateSyncGatewayRAM estimates the RAM required for the Sync Gateway nodes.
+func CalculateSyncGatewayRAM(dataset models.Dataset, syncGateway models.SyncGatewayWorkload) float64 {
+	// Constants
+	const baseMemory = 1.0 * 1024 * 1024 * 1024			// Sync Gateway process (In bytes)
+	const memoryPerConnection = 100 * 1024					// websocket / continuous changes feed buffers per device (In bytes)
+	const channelCacheEntries = 50									// recent changes cached per channel
+	const channelCacheEntrySize = 200								// size of a channel cache entry (In bytes)
+	const revisionCacheSize = 5000									// documents held in the revision cache
+
+	// Step 1: Memory for connected devices (In bytes)
+	connectionMemory := float64(syncGateway.ConnectedDevices) * memoryPerConnection
+
+	// Step 2: Channel cache (In bytes)
+	// channels are assumed to be per user, shared channels only lower the estimate
+	channels := float64(syncGateway.ConnectedDevices) * float64(max(syncGateway.ChannelsPerUser, 1))
+	channelCache := channels * channelCacheEntries * channelCacheEntrySize
+
+	// Step 3: Revision cache (In bytes)
+	revisionCache := revisionCacheSize * (float64(dataset.AverageDocumentSize) + syncMetadataBytesPerDocument(syncGateway))
+
+	// Step 4: Recommended RAM (In GB)
+	recommendedRam := math.Ceil((baseMemory + connectionMemory + channelCache + revisionCache) / 1024 / 1024 / 1024)
+
+	return recommendedRam
+}
+
+// CalculateSyncGatewayCPU estimates the CPU required for the Sync Gateway nodes.
+func CalculateSyncGatewayCPU(workload models.Workload, syncGateway models.SyncGatewayWorkload) float64 {
+	// Constants
+	const connectionsPerCore = 2500.0			// connected devices a single core keeps alive
+	const changesPerCore = 5000.0					// changes feed entries a single core delivers per second
+	const importsPerCore = 2000.0					// SDK writes a single core imports per second
+
+	// Step 1: Cores for the connected devices
+	connectionCoresReq := float64(syncGateway.ConnectedDevices) / connectionsPerCore
+
+	// Step 2: Cores for the changes feed, channel assignment grows with the channels per user
+	changesCoresReq := float64(syncGateway.ChangesFeedPerSec) * (1 + float64(syncGateway.ChannelsPerUser)/100) / changesPerCore
+
+	// Step 3: Cores for import processing
+	importCoresReq := syncGatewayImportsPerSec(workload, syncGateway) / importsPerCore
+
+	// Step 4: Recommended Cores
+	recommendedCores := math.Ceil(max((connectionCoresReq+changesCoresReq+importCoresReq)*1.2, 1))
+
+	return recommendedCores
+}
+
+// SyncGatewayDataOverhead returns the load Sync Gateway adds to the Data service.
+// Imports load the Data service without connected devices, the changes feed and attachments come with the devices.
+func SyncGatewayDataOverhead(dataset models.Dataset, workload models.Workload, syncGateway models.SyncGatewayWorkload) DataOverhead {
+	// Constants
+	const revisionCacheMissRatio = 0.5			// share of changes feed entries fetched from the Data service
+
+	var overhead DataOverhead
+
+	// Step 1: Imported writes update the _sync metadata and allocate a sequence
+	if importsPerSec := syncGatewayImportsPerSec(workload, syncGateway); importsPerSec > 0 {
+		overhead.ExtraWritesPerSec = importsPerSec * 2
+	}
+
+	// Step 2: Changes feed entries missing the revision cache are read from the Data service
+	if syncGateway.ConnectedDevices > 0 {
+		overhead.ExtraReadsPerSec = float64(syncGateway.ChangesFeedPerSec) * revisionCacheMissRatio
+	}
+
+	// Step 3: Attachments pushed by the devices are stored as documents of their own
+	if syncGateway.ConnectedDevices > 0 {
+		attachments := float64(dataset.NoOfDocuments) * float64(syncGateway.PercentDocumentsWithAttachments) / 100
+		overhead.ExtraDocuments = attachments
+		overhead.ExtraDocumentBytes = attachments * float64(syncGateway.AverageAttachmentSize)
+	}
+
+	// Step 4: Documents written by the devices or imported carry the _sync metadata, the import and channel cache feed is a DCP consumer
+	if overhead.ExtraWritesPerSec > 0 || syncGateway.ConnectedDevices > 0 {
+		overhead.XattrBytesPerDocument = syncMetadataBytesPerDocument(syncGateway)
+		overhead.DCPConsumers = 1
+	}
+	return overhead
+}
+
+// syncMetadataBytesPerDocument returns the size of the _sync xattr Sync Gateway keeps on every document (In bytes)
+func syncMetadataBytesPerDocument(syncGateway models.SyncGatewayWorkload) float64 {
+	const baseMetadata = 200						// sequence, current revision and flags
+	const revisionHistory = 20 * 40			// revision tree kept per document (revs_limit x revision id)
+	const bytesPerChannel = 50					// channel membership entry
+	const attachmentMetadata = 100			// digest and length of the attachments
+
+	metadata := float64(baseMetadata + revisionHistory + bytesPerChannel*max(syncGateway.ChannelsPerUser, 1))
+	if syncGateway.PercentDocumentsWithAttachments > 0 {
+		metadata += attachmentMetadata * float64(syncGateway.PercentDocumentsWithAttachments) / 100
+	}
+	return metadata
+}
+
+// syncGatewayImportsPerSec returns the Data writes imported by Sync Gateway per second
+func syncGatewayImportsPerSec(workload models.Workload, syncGateway models.SyncGatewayWorkload) float64 {
+	return float64(workload.WritesPerSec) * float64(syncGateway.PercentImportProcessing) / 100
+}
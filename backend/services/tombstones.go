@@ -0,0 +1,43 @@
+package services
+
+import (
+	"math"
+	"workload-estimator-poc/models"
+)
+
+// defaultPurgeIntervalDays is the metadata purge interval of Couchbase Server
+const defaultPurgeIntervalDays = 3.0
+
+// Purge intervals the components replaying deletes need, a shorter interval loses the deletes of a consumer that is behind
+const (
+	MinPurgeIntervalDaysXDCR        = 7.0  // replications paused or behind for up to a week
+	MinPurgeIntervalDaysSyncGateway = 60.0 // mobile clients offline for up to the Sync Gateway recommendation
+)
+
+// PurgeIntervalDays returns the metadata purge interval of the bucket (In days)
+func PurgeIntervalDays(dataset models.Dataset) float64 {
+	if dataset.Bucket.PurgeIntervalDays <= 0 {
+		return defaultPurgeIntervalDays
+	}
+	return dataset.Bucket.PurgeIntervalDays
+}
+
+// TombstoneSpace computes the disk space held by tombstones of deleted documents until they are purged (In GB)
+func TombstoneSpace(dataset models.Dataset, workload models.Workload, xdcrReplications int64) float64 {
+	return Round(tombstoneBytes(dataset, workload, 0, float64(xdcrReplications))/1024/1024/1024, 2)
+}
+
+// tombstoneBytes computes the space held by the tombstones of deleted and expired documents of every copy until they are purged (In bytes).
+// Every XDCR replication keeps metadata of its own with the tombstone.
+func tombstoneBytes(dataset models.Dataset, workload models.Workload, expiryOpsPerSec, xdcrReplications float64) float64 {
+	// Constants
+	const tombstoneMetadata = 60				// metadata kept per tombstone and replication (In bytes)
+
+	// Step 1: Tombstones created per day by all the copies
+	numReplicas := float64(BucketReplicas(dataset))
+	tombstonesPerDay := (float64(workload.DeletesPerSec) + expiryOpsPerSec) * (numReplicas + 1) * 60 * 60 * 24
+
+	// Step 2: Tombstones kept for the purge interval
+	metadata := tombstoneMetadata * math.Max(1, xdcrReplications)
+	return (float64(dataset.AverageKeySize) + metadata) * PurgeIntervalDays(dataset) * tombstonesPerDay
+}
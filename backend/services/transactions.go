@@ -0,0 +1,66 @@
+package services
+
+import (
+	"math"
+	"workload-estimator-poc/models"
+)
+
+// defaultPercentTransactionWrites is the share of the documents a transaction writes when the request does not say, transactions usually exist to write
+const defaultPercentTransactionWrites = 100
+
+// transactionWritePercent returns the % of the documents of a transaction that are written
+func transactionWritePercent(workload models.Workload) float64 {
+	if workload.PercentTransactionWrites == nil {
+		return defaultPercentTransactionWrites
+	}
+	return math.Min(math.Max(float64(*workload.PercentTransactionWrites), 0), 100)
+}
+
+// TransactionDataOverhead returns the load multi-document ACID transactions add to the Data service
+func TransactionDataOverhead(dataset models.Dataset, workload models.Workload) DataOverhead {
+	// Constants
+	const numATRs = 1024								// Active Transaction Records of the bucket, one per vBucket
+	const atrWritesPerTransaction = 3		// pending, committed and completed entries of the attempt
+	const atrBaseSize = 100							// ATR document without entries (In bytes)
+	const atrEntrySize = 200						// attempt entry in the ATR without its document ids (In bytes)
+	const txnXattrSize = 250						// "txn" xattr staging the mutation on the document (In bytes)
+	const transactionDuration = 1.0			// time an attempt stays in its ATR and its mutations stay staged, cleanup included (In seconds)
+	const cleanupWindow = 60.0					// interval in which the cleanup polls every ATR (In seconds)
+
+	if workload.TransactionsPerSec == 0 {
+		return DataOverhead{}
+	}
+
+	// Step 1: Documents read and written by a transaction
+	documentsPerTransaction := float64(max(workload.DocumentsPerTransaction, 1))
+	writesPerTransaction := documentsPerTransaction * transactionWritePercent(workload) / 100
+	readsPerTransaction := documentsPerTransaction - writesPerTransaction
+	transactionsPerSec := float64(workload.TransactionsPerSec)
+
+	// Step 2: Writes are staged and then committed, writing transactions update their ATR entry
+	writesPerSec := transactionsPerSec * writesPerTransaction * 2
+	if writesPerTransaction > 0 {
+		writesPerSec += transactionsPerSec * atrWritesPerTransaction
+	}
+
+	// Step 3: Documents are read before they are written, the cleanup polls every ATR
+	readsPerSec := transactionsPerSec*(readsPerTransaction+writesPerTransaction) + numATRs/cleanupWindow
+
+	// Step 4: Staged mutations are held in the document's xattrs until they are committed (averaged over every document)
+	stagedDocuments := transactionsPerSec * writesPerTransaction * transactionDuration
+	stagedBytes := stagedDocuments * (txnXattrSize + float64(dataset.AverageDocumentSize))
+	xattrBytes := stagedBytes / math.Max(float64(dataset.NoOfDocuments), 1)
+
+	// Step 5: ATRs are documents of their own holding an entry per running attempt
+	attemptsInFlight := transactionsPerSec * transactionDuration
+	atrBytes := numATRs*(atrBaseSize+float64(dataset.AverageKeySize)) +
+		attemptsInFlight*(atrEntrySize+writesPerTransaction*float64(dataset.AverageKeySize))
+
+	return DataOverhead{
+		ExtraReadsPerSec:      readsPerSec,
+		ExtraWritesPerSec:     writesPerSec,
+		XattrBytesPerDocument: xattrBytes,
+		ExtraDocuments:        numATRs,
+		ExtraDocumentBytes:    atrBytes,
+	}
+}
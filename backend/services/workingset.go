@@ -0,0 +1,113 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"workload-estimator-poc/models"
+)
+
+// RecommendResidentRatio recommends the lowest resident ratio whose cache-miss rate stays within the target
+// of the access pattern, and reports how Data RAM trades off against miss-driven disk reads.
+func RecommendResidentRatio(dataset models.Dataset, workload models.Workload, overhead DataOverhead) models.WorkingSetResult {
+	// Constants
+	const minimumResidentRatio = 10					// Couchstore minimum resident ratio
+	const defaultTargetCacheMissPercent = 5.0
+
+	dataset, workload = applyDataOverhead(dataset, workload, overhead)
+
+	targetMissRatio := dataset.AccessPattern.TargetCacheMissPercent / 100
+	if targetMissRatio <= 0 {
+		targetMissRatio = defaultTargetCacheMissPercent / 100
+	}
+
+	// Step 1: Lowest resident ratio meeting the cache-miss target
+	var recommended int64 = 100
+	for residentRatio := int64(minimumResidentRatio); residentRatio <= 100; residentRatio++ {
+		if 1-cacheHitRatio(dataset, workload, float64(residentRatio)/100) <= targetMissRatio {
+			recommended = residentRatio
+			break
+		}
+	}
+
+	// Step 2: RAM and disk reads at several resident ratio points
+	points := []int64{recommended}
+	for residentRatio := int64(minimumResidentRatio); residentRatio <= 100; residentRatio += 10 {
+		if residentRatio != recommended {
+			points = append(points, residentRatio)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	var tradeOffs []models.ResidentRatioPoint
+	for _, residentRatio := range points {
+		pointDataset := dataset
+		pointDataset.ResidentRatio = residentRatio
+		missRatio := 1 - cacheHitRatio(dataset, workload, float64(residentRatio)/100)
+		tradeOffs = append(tradeOffs, models.ResidentRatioPoint{
+			ResidentRatio:    residentRatio,
+			DataRAM:          int64(calculateDataRAM(pointDataset, workload, overhead.DCPConsumers, overhead.XDCRReplications)),
+			CacheMissPercent: Round(missRatio*100, 2),
+			DiskReadIOPS:     int64(math.Ceil(float64(workload.ReadPerSec) * missRatio)),
+		})
+	}
+
+	return models.WorkingSetResult{
+		RecommendedResidentRatio: recommended,
+		CacheMissPercent:         Round((1-cacheHitRatio(dataset, workload, float64(recommended)/100))*100, 2),
+		TradeOffs:                tradeOffs,
+	}
+}
+
+// cacheHitRatio returns the share of reads served from memory when residentFraction of the documents are resident.
+// The most frequently read documents are assumed to be the resident ones.
+func cacheHitRatio(dataset models.Dataset, workload models.Workload, residentFraction float64) float64 {
+	residentFraction = math.Min(math.Max(residentFraction, 0), 1)
+	pattern := dataset.AccessPattern
+
+	switch pattern.Model {
+	case "hot_set":
+		return hotSetHitRatio(pattern.HotSetPercent/100, pattern.HotSetReadPercent/100, residentFraction)
+	case "recent_data":
+		// documents written in the last RecentDays form the hot set
+		recentDocuments := float64(workload.WritesPerSec) * pattern.RecentDays * 24 * 3600
+		hotSetFraction := math.Min(recentDocuments/math.Max(float64(dataset.NoOfDocuments), 1), 1)
+		return hotSetHitRatio(hotSetFraction, pattern.PercentReadsRecent/100, residentFraction)
+	case "zipfian":
+		documents := math.Max(float64(dataset.NoOfDocuments), 1)
+		residentDocuments := math.Max(residentFraction*documents, 1)
+		if residentFraction == 0 {
+			return 0
+		}
+		return math.Min(generalizedHarmonic(residentDocuments, pattern.ZipfExponent)/generalizedHarmonic(documents, pattern.ZipfExponent), 1)
+	default:
+		// uniform access, every document is equally likely to be read
+		return residentFraction
+	}
+}
+
+// hotSetHitRatio returns the hit ratio when hotSetFraction of the documents receive hotReadFraction of the reads
+func hotSetHitRatio(hotSetFraction, hotReadFraction, residentFraction float64) float64 {
+	hotSetFraction = math.Min(math.Max(hotSetFraction, 0), 1)
+	hotReadFraction = math.Min(math.Max(hotReadFraction, 0), 1)
+	if hotSetFraction == 0 {
+		return hotReadFraction + (1-hotReadFraction)*residentFraction
+	}
+	if residentFraction <= hotSetFraction {
+		return hotReadFraction * residentFraction / hotSetFraction
+	}
+	if hotSetFraction == 1 {
+		return 1
+	}
+	return hotReadFraction + (1-hotReadFraction)*(residentFraction-hotSetFraction)/(1-hotSetFraction)
+}
+
+// generalizedHarmonic approximates the generalized harmonic number H(n, s) = sum of 1/k^s for k = 1..n
+func generalizedHarmonic(n, s float64) float64 {
+	if s <= 0 {
+		return n
+	}
+	if math.Abs(s-1) < 1e-9 {
+		return math.Log(n) + 0.5772156649
+	}
+	return 1 + (math.Pow(n, 1-s)-1)/(1-s)
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+	"workload-estimator-poc/models"
+)
+
+func TestRecommendResidentRatio(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern models.AccessPattern
+		want    int64
+	}{
+		{
+			name:    "uniform access keeps all but the target misses resident",
+			pattern: models.AccessPattern{TargetCacheMissPercent: 7.5},
+			want:    93,
+		},
+		{
+			name:    "hot set beyond its documents",
+			pattern: models.AccessPattern{Model: "hot_set", HotSetPercent: 20, HotSetReadPercent: 85},
+			want:    74,
+		},
+		{
+			name:    "a small hot set stops at the Couchstore minimum",
+			pattern: models.AccessPattern{Model: "hot_set", HotSetPercent: 5, HotSetReadPercent: 99},
+			want:    10,
+		},
+		{
+			name:    "recent data written within the hot days",
+			pattern: models.AccessPattern{Model: "recent_data", RecentDays: 1, PercentReadsRecent: 90, TargetCacheMissPercent: 10},
+			want:    18,
+		},
+		{
+			name:    "a target no ratio meets keeps everything resident",
+			pattern: models.AccessPattern{Model: "hot_set", HotSetPercent: 50, HotSetReadPercent: 50, TargetCacheMissPercent: 0.001},
+			want:    100,
+		},
+	}
+	for _, test := range tests {
+		dataset := models.Dataset{NoOfDocuments: 10000000, AverageDocumentSize: 1024, AverageKeySize: 40, AccessPattern: test.pattern}
+		workload := models.Workload{ReadPerSec: 10000, WritesPerSec: 20}
+		got := RecommendResidentRatio(dataset, workload, DataOverhead{})
+		if got.RecommendedResidentRatio != test.want {
+			t.Errorf("%s: recommended resident ratio = %d, want %d", test.name, got.RecommendedResidentRatio, test.want)
+		}
+		if len(got.TradeOffs) == 0 {
+			t.Errorf("%s: no trade-off points", test.name)
+		}
+	}
+}
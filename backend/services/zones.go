@@ -0,0 +1,25 @@
+package services
+
+import (
+	"workload-estimator-poc/models"
+)
+
+// InterZoneTraffic computes the traffic between availability zones caused by the Data service (In bytes per second).
+// Every replica is placed in another zone than its active, DCP consumers sit in any zone of their own group.
+// Client traffic is left out, its placement is not known.
+func InterZoneTraffic(dataset models.Dataset, workload models.Workload, overhead DataOverhead, zones int64) (replication, dcp float64) {
+	if zones <= 1 {
+		return 0, 0
+	}
+	dataset, workload = applyDataOverhead(dataset, workload, overhead)
+
+	// Step 1: Every mutation is sent to each replica
+	mutationsPerSec := float64(workload.WritesPerSec + workload.DeletesPerSec)
+	replication = mutationsPerSec * mutationBytes(dataset) * float64(BucketReplicas(dataset))
+
+	// Step 2: Consumers outside the zone of the active vBucket receive its mutations from another zone
+	crossZoneShare := float64(zones-1) / float64(zones)
+	dcp = mutationsPerSec * mutationBytes(dataset) * overhead.DCPConsumers * crossZoneShare
+
+	return replication, dcp
+}